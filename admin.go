@@ -0,0 +1,173 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/adampresley/httphelpers/requests"
+	"github.com/adampresley/httphelpers/responses"
+	"github.com/adampresley/mux"
+)
+
+/*
+adminRoutes returns the routes for administrative endpoints. Every route
+is wrapped in requireAdmin.
+*/
+func adminRoutes() []mux.Route {
+	adminMiddlewares := []mux.MiddlewareFunc{requireAdmin}
+
+	return []mux.Route{
+		{Path: "GET /admin/config", HandlerFunc: adminConfigHandler(), Middlewares: adminMiddlewares},
+		{Path: "POST /admin/check", HandlerFunc: adminCheckHandler(), Middlewares: adminMiddlewares},
+		{Path: "GET /admin/mutes", HandlerFunc: adminListMutesHandler(), Middlewares: adminMiddlewares},
+		{Path: "POST /admin/mutes", HandlerFunc: adminMuteHandler(), Middlewares: adminMiddlewares},
+		{Path: "DELETE /admin/mutes/{serviceName}", HandlerFunc: adminUnmuteHandler(), Middlewares: adminMiddlewares},
+		{Path: "POST /admin/services/{serviceName}/disable", HandlerFunc: adminSetServiceActiveHandler(false), Middlewares: adminMiddlewares},
+		{Path: "POST /admin/services/{serviceName}/enable", HandlerFunc: adminSetServiceActiveHandler(true), Middlewares: adminMiddlewares},
+		{Path: "POST /admin/notify/test", HandlerFunc: adminTestNotifyHandler(), Middlewares: adminMiddlewares},
+		{Path: "GET /admin/transitions", HandlerFunc: adminTransitionsHandler(), Middlewares: adminMiddlewares},
+		{Path: "GET /debug/parse", HandlerFunc: debugParseHandler(), Middlewares: adminMiddlewares},
+	}
+}
+
+/*
+requireAdmin rejects any request that does not carry the configured
+admin token as a bearer token.
+*/
+func requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err   error
+			token string
+		)
+
+		if token, err = requests.AuthorizationBearer(r); err != nil {
+			responses.JsonErrorMessage(w, http.StatusUnauthorized, "missing or invalid authorization header")
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(token), []byte(config.AdminToken)) != 1 {
+			responses.JsonErrorMessage(w, http.StatusUnauthorized, "invalid admin token")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func adminTransitionsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err         error
+			transitions []*StatusTransition
+		)
+
+		if transitions, err = queryStatusTransitions(parseLimit(r, 50, 500)); err != nil {
+			slog.Error("error querying status transitions", "error", err)
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, "An unexpected error occurred while querying status transitions")
+			return
+		}
+
+		writeJsonOK(w, r, transitions)
+	}
+}
+
+/*
+adminConfigHandler returns the running configuration with the DSN password
+and tokens masked.
+*/
+func adminConfigHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJsonOK(w, r, redactedConfig())
+	}
+}
+
+/*
+adminCheckHandler runs a status check immediately, outside the cron
+schedule, and returns its CronResult. It takes the same lock as the
+scheduled check, so it responds with 409 Conflict rather than run
+alongside a check already in progress on any replica.
+*/
+func adminCheckHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err      error
+			services []*Service
+			statuses []*Status
+		)
+
+		locker := &PostgresLocker{DB: db}
+
+		if err = locker.Lock(r.Context(), checkStatusLockKey); err != nil {
+			if errors.Is(err, ErrCronLockInUse) {
+				responses.JsonErrorMessage(w, http.StatusConflict, "a status check is already running")
+				return
+			}
+
+			slog.Error("error obtaining cron lock for manual check", "error", err)
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, "An unexpected error occurred while obtaining the check lock")
+			return
+		}
+
+		defer func() {
+			ctx, cancel := getContext()
+			defer cancel()
+
+			if err := locker.Unlock(ctx, checkStatusLockKey); err != nil {
+				slog.Error("error releasing cron lock for manual check", "error", err)
+			}
+		}()
+
+		if services, err = queryServices(); err != nil {
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if statuses, err = queryStatuses(); err != nil {
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		result := safeCronJob(services, statuses)
+
+		if result.Err != nil {
+			responses.JsonErrorMessage(w, http.StatusBadGateway, result.Err.Error())
+			return
+		}
+
+		writeJsonOK(w, r, result)
+	}
+}
+
+/*
+adminSetServiceActiveHandler enables or disables a service by name. A
+disabled service keeps its history but is no longer expected on the
+status page or written to the feed. Enabling a service also clears its
+missing run count.
+*/
+func adminSetServiceActiveHandler(active bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err     error
+			updated int
+		)
+
+		serviceName := r.PathValue("serviceName")
+
+		if updated, err = updateServiceActiveByName(serviceName, active); err != nil {
+			slog.Error("error updating service", "service", serviceName, "active", active, "error", err)
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, "An unexpected error occurred while updating the service")
+			return
+		}
+
+		if updated == 0 {
+			responses.JsonErrorMessage(w, http.StatusNotFound, "unknown service")
+			return
+		}
+
+		slog.Info("service updated", "service", serviceName, "active", active)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestAdminCheckHandlerConflictsWithRunningCheck(t *testing.T) {
+	setupTestDB(t)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if err := gorm.G[CronLock](db).Create(ctx, &CronLock{Key: checkStatusLockKey}); err != nil {
+		t.Fatalf("error creating cron lock: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	adminCheckHandler()(w, httptest.NewRequest(http.MethodPost, "/admin/check", nil))
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusConflict, w.Code, w.Body.String())
+	}
+
+	count, err := gorm.G[CronLock](db).Where("key=?", checkStatusLockKey).Count(ctx, "*")
+
+	if err != nil {
+		t.Fatalf("error counting cron locks: %v", err)
+	}
+
+	if count != 1 {
+		t.Errorf("expected the running check's lock to be left in place, found %d", count)
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	setTestConfig(t, &Config{AdminToken: "secret"})
+
+	handler := requireAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for token, want := range map[string]int{"": http.StatusUnauthorized, "wrong": http.StatusUnauthorized, "secret": http.StatusNoContent} {
+		r := httptest.NewRequest(http.MethodGet, "/admin/transitions", nil)
+
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != want {
+			t.Errorf("token %q: expected status %d, got %d", token, want, w.Code)
+		}
+	}
+}
+
+func TestStatusTransitionsAreRecorded(t *testing.T) {
+	var (
+		transitions []*StatusTransition
+	)
+
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, testPageOutage)
+	runTestCheck(t, testPageOutage)
+
+	w := httptest.NewRecorder()
+	adminTransitionsHandler()(w, httptest.NewRequest(http.MethodGet, "/admin/transitions?limit=10", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	if err := json.Unmarshal(w.Body.Bytes(), &transitions); err != nil {
+		t.Fatalf("error decoding transitions: %v", err)
+	}
+
+	if len(transitions) != 2 {
+		t.Fatalf("expected the first check and one change to be recorded, got %d", len(transitions))
+	}
+
+	newest := transitions[0]
+
+	if newest.AffectedServices != "Storefront" || newest.PreviousHash != transitions[1].NewHash || newest.FeedID == 0 {
+		t.Errorf("unexpected transition %+v", newest)
+	}
+
+	if transitions[1].PreviousHash != "" {
+		t.Errorf("expected the first transition to have no previous hash, got %q", transitions[1].PreviousHash)
+	}
+}
+
+func TestAdminSetServiceActiveHandler(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	setActive := func(serviceName string, active bool) int {
+		action := "disable"
+
+		if active {
+			action = "enable"
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/admin/services/"+serviceName+"/"+action, nil)
+		r.SetPathValue("serviceName", serviceName)
+
+		w := httptest.NewRecorder()
+		adminSetServiceActiveHandler(active)(w, r)
+		return w.Code
+	}
+
+	if code := setActive("Storefront", false); code != http.StatusNoContent {
+		t.Fatalf("expected 204 disabling Storefront, got %d", code)
+	}
+
+	services, err := queryServices()
+
+	if err != nil {
+		t.Fatalf("error querying services: %v", err)
+	}
+
+	if len(services) != 1 || services[0].ServiceName != "Checkout" {
+		t.Errorf("expected only Checkout to be checked, got %+v", services)
+	}
+
+	/*
+	 * Enabling a service clears any missing runs that led to it being
+	 * disabled automatically.
+	 */
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if _, err = gorm.G[Service](db).Where("service_name=?", "Storefront").Update(ctx, "missing_runs", 3); err != nil {
+		t.Fatalf("error setting missing runs: %v", err)
+	}
+
+	if code := setActive("Storefront", true); code != http.StatusNoContent {
+		t.Fatalf("expected 204 enabling Storefront, got %d", code)
+	}
+
+	if storefront := queryTestService(t, "Storefront"); !storefront.Active || storefront.MissingRuns != 0 {
+		t.Errorf("expected Storefront active with missing runs cleared, got %+v", storefront)
+	}
+
+	if code := setActive("Payments", false); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown service, got %d", code)
+	}
+}
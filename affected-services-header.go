@@ -0,0 +1,35 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+/*
+setAffectedServicesHeader sets X-Affected-Services to the number of
+services in error in the latest snapshot, so a HEAD request shows the
+headline without parsing the feed. The header is left off when there is
+no snapshot yet.
+*/
+func setAffectedServicesHeader(w http.ResponseWriter) {
+	var (
+		err        error
+		lastStatus *LastStatus
+	)
+
+	if lastStatus, err = queryLastStatus(); err != nil {
+		slog.Debug("no last status for the affected services header", "error", err)
+		return
+	}
+
+	count := 0
+
+	for _, state := range parseSnapshot(lastStatus.Snapshot) {
+		if state.IsError {
+			count++
+		}
+	}
+
+	w.Header().Set("X-Affected-Services", strconv.Itoa(count))
+}
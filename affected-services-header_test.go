@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAffectedServicesHeader(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	runTestCheck(t, testPageOutage)
+
+	if _, w := getTestRss(t, "/status.rss"); w.Header().Get("X-Affected-Services") != "" {
+		t.Errorf("expected no header when disabled, got %q", w.Header().Get("X-Affected-Services"))
+	}
+
+	config.AffectedServicesHeader = true
+
+	if _, w := getTestRss(t, "/status.rss"); w.Header().Get("X-Affected-Services") != "1" {
+		t.Errorf("expected 1 affected service, got %q", w.Header().Get("X-Affected-Services"))
+	}
+
+	runTestCheck(t, testPageOperational)
+
+	/*
+	 * The header is also set on a 304, for clients polling with HEAD or
+	 * If-None-Match.
+	 */
+	_, w := getTestRss(t, "/status.rss")
+
+	r := httptest.NewRequest(http.MethodGet, "/status.rss", nil)
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+
+	w = httptest.NewRecorder()
+	statusRssHandler()(w, r)
+
+	if w.Code != http.StatusNotModified || w.Header().Get("X-Affected-Services") != "0" {
+		t.Errorf("expected a 304 with 0 affected services, got %d %q", w.Code, w.Header().Get("X-Affected-Services"))
+	}
+}
+
+func TestAffectedServicesHeaderBeforeFirstCheck(t *testing.T) {
+	setupTestDB(t)
+	config.AffectedServicesHeader = true
+
+	if _, w := getTestRss(t, "/status.rss"); w.Header().Get("X-Affected-Services") != "" {
+		t.Errorf("expected no header without a snapshot, got %q", w.Header().Get("X-Affected-Services"))
+	}
+}
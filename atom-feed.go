@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+/*
+AtomFeed is an Atom 1.0 document. See https://www.rfc-editor.org/rfc/rfc4287
+*/
+type AtomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Subtitle  string      `xml:"subtitle"`
+	Updated   string      `xml:"updated"`
+	Link      AtomLink    `xml:"link"`
+	Author    *AtomAuthor `xml:"author,omitempty"`
+	Generator string      `xml:"generator"`
+	Entries   []AtomEntry `xml:"entry"`
+}
+
+type AtomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type AtomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    AtomLink    `xml:"link"`
+	Content AtomContent `xml:"content"`
+}
+
+type AtomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func renderAtomFeed(feed []*Feed) ([]byte, error) {
+	var (
+		err error
+		b   []byte
+	)
+
+	updated := time.Now().UTC()
+
+	if len(feed) > 0 {
+		updated = feed[0].PubDate
+	}
+
+	result := AtomFeed{
+		ID:        config.StatusPageURL,
+		Title:     feedTitle,
+		Subtitle:  feedDescription,
+		Updated:   updated.Format(time.RFC3339),
+		Link:      AtomLink{Href: config.StatusPageURL, Rel: "alternate"},
+		Generator: feedGenerator,
+		Entries:   []AtomEntry{},
+	}
+
+	if config.FeedAuthor != "" {
+		result.Author = &AtomAuthor{Name: config.FeedAuthor}
+	}
+
+	for _, f := range feed {
+		result.Entries = append(result.Entries, AtomEntry{
+			ID:      fmt.Sprintf("%s#%d", config.StatusPageURL, f.ID),
+			Title:   f.Title,
+			Updated: f.PubDate.Format(time.RFC3339),
+			Link:    AtomLink{Href: f.GetLink(), Rel: "alternate"},
+			Content: AtomContent{Type: "html", Body: f.Description},
+		})
+	}
+
+	if b, err = xml.Marshal(result); err != nil {
+		return b, err
+	}
+
+	return append([]byte(xml.Header), b...), nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+/*
+channelDescription returns the RSS channel description. When
+Config.DynamicChannelDescription is on, the current overall state from
+the latest snapshot is added to the static description.
+*/
+func channelDescription() string {
+	var (
+		err        error
+		lastStatus *LastStatus
+	)
+
+	if !config.DynamicChannelDescription {
+		return feedDescription
+	}
+
+	if lastStatus, err = queryLastStatus(); err != nil {
+		slog.Debug("no last status for the channel description", "error", err)
+		return feedDescription
+	}
+
+	summary := describeSnapshot(parseSnapshot(lastStatus.Snapshot))
+
+	if summary == "" {
+		return feedDescription
+	}
+
+	return feedDescription + " " + summary
+}
+
+/*
+describeSnapshot summarizes stored service states in one sentence, such
+as "Currently: 2 services degraded (Admin, Checkout)."
+*/
+func describeSnapshot(snapshot []ServiceState) string {
+	names := []string{}
+
+	for _, state := range snapshot {
+		if state.IsError {
+			names = append(names, state.ServiceName)
+		}
+	}
+
+	switch len(names) {
+	case 0:
+		if len(snapshot) == 0 {
+			return ""
+		}
+
+		return "Currently: all systems operational."
+
+	case 1:
+		return fmt.Sprintf("Currently: 1 service degraded (%s).", names[0])
+
+	default:
+		return fmt.Sprintf("Currently: %d services degraded (%s).", len(names), strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDescribeSnapshot(t *testing.T) {
+	tests := []struct {
+		name     string
+		snapshot []ServiceState
+		want     string
+	}{
+		{name: "no snapshot", snapshot: nil, want: ""},
+		{name: "operational", snapshot: []ServiceState{{ServiceName: "Checkout"}, {ServiceName: "Storefront"}}, want: "Currently: all systems operational."},
+		{name: "one degraded", snapshot: []ServiceState{{ServiceName: "Checkout"}, {ServiceName: "Storefront", IsError: true}}, want: "Currently: 1 service degraded (Storefront)."},
+		{
+			name:     "several degraded",
+			snapshot: []ServiceState{{ServiceName: "Admin", IsError: true}, {ServiceName: "Checkout", IsError: true}, {ServiceName: "Storefront"}},
+			want:     "Currently: 2 services degraded (Admin, Checkout).",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeSnapshot(tt.snapshot); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDynamicChannelDescription(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.DynamicChannelDescription = true
+
+	if feed, _ := getTestRss(t, "/status.rss"); feed.Channel.Description != feedDescription {
+		t.Errorf("expected the static description before the first check, got %q", feed.Channel.Description)
+	}
+
+	runTestCheck(t, testPageOutage)
+
+	want := feedDescription + " Currently: 1 service degraded (Storefront)."
+
+	if feed, _ := getTestRss(t, "/status.rss"); feed.Channel.Description != want {
+		t.Errorf("expected %q, got %q", want, feed.Channel.Description)
+	}
+
+	config.DynamicChannelDescription = false
+
+	if got := channelDescription(); got != feedDescription {
+		t.Errorf("expected the static description when disabled, got %q", got)
+	}
+}
+
+func TestDynamicChannelDescriptionIsNotServedFromAnOlderState(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.DynamicChannelDescription = true
+	config.CacheFeedInDB = true
+	config.FeedCacheTTL = time.Minute
+
+	/*
+	 * A minor outage below the minimum severity writes no feed item, so
+	 * only the status hash says the cached renderings are out of date.
+	 */
+	config.MinFeedSeverity = SeverityMajor
+	config.MajorSeverityFraction = 0.9
+
+	runTestCheck(t, testPageOperational)
+	getTestRss(t, "/status.rss")
+	runTestCheck(t, testPageOutage)
+
+	if feed := queryTestFeed(t); len(feed) != 1 {
+		t.Fatalf("expected only the first-run item, got %d", len(feed))
+	}
+
+	want := feedDescription + " Currently: 1 service degraded (Storefront)."
+
+	if feed, _ := getTestRss(t, "/status.rss"); feed.Channel.Description != want {
+		t.Errorf("expected %q from the memory cache, got %q", want, feed.Channel.Description)
+	}
+
+	/*
+	 * Another replica's memory cache is empty, so it reads the shared
+	 * database cache, which must also be current.
+	 */
+	clearMemoryFeedCache()
+
+	if feed, _ := getTestRss(t, "/status.rss"); feed.Channel.Description != want {
+		t.Errorf("expected %q from the database cache, got %q", want, feed.Channel.Description)
+	}
+}
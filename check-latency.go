@@ -0,0 +1,65 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	/*
+	 * latencyEmaAlpha weights the newest run. Higher values follow changes
+	 * faster but make the average noisier.
+	 */
+	latencyEmaAlpha = 0.2
+
+	/*
+	 * latencyEmaWarmup is the number of runs averaged before anomalies
+	 * are reported, so the first few samples don't trigger warnings.
+	 */
+	latencyEmaWarmup = 5
+)
+
+/*
+latencyEma is the exponential moving average of fetch and parse latency
+across checks, kept in memory.
+*/
+var latencyEma = struct {
+	sync.Mutex
+	average time.Duration
+	samples int
+}{}
+
+/*
+latencyAnomalies counts checks slower than the average by
+Config.LatencyAnomalyMultiplier, reported by /healthz.
+*/
+var latencyAnomalies atomic.Int64
+
+/*
+observeCheckLatency folds a check's latency into the moving average and
+warns when it exceeds the average by Config.LatencyAnomalyMultiplier. A
+slow check is an early sign of upstream trouble before it starts failing.
+*/
+func observeCheckLatency(latency time.Duration) {
+	latencyEma.Lock()
+	defer latencyEma.Unlock()
+
+	if config.LatencyAnomalyMultiplier > 0 && latencyEma.samples >= latencyEmaWarmup {
+		threshold := time.Duration(float64(latencyEma.average) * config.LatencyAnomalyMultiplier)
+
+		if latency > threshold {
+			latencyAnomalies.Add(1)
+			slog.Warn("status check was unusually slow", "latency", latency, "average", latencyEma.average, "threshold", threshold)
+		}
+	}
+
+	if latencyEma.samples == 0 {
+		latencyEma.average = latency
+	} else {
+		latencyEma.average = time.Duration(latencyEmaAlpha*float64(latency) + (1-latencyEmaAlpha)*float64(latencyEma.average))
+	}
+
+	latencyEma.samples++
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestObserveCheckLatency(t *testing.T) {
+	setTestConfig(t, &Config{LatencyAnomalyMultiplier: 3})
+	t.Cleanup(resetTestState)
+	resetTestState()
+
+	anomalies := latencyAnomalies.Load()
+
+	/*
+	 * A slow run during warm-up is averaged but not reported.
+	 */
+	observeCheckLatency(time.Second)
+
+	for range latencyEmaWarmup - 1 {
+		observeCheckLatency(100 * time.Millisecond)
+	}
+
+	if got := latencyAnomalies.Load() - anomalies; got != 0 {
+		t.Fatalf("expected no anomalies during warm-up, got %d", got)
+	}
+
+	latencyEma.Lock()
+	average := latencyEma.average
+	latencyEma.Unlock()
+
+	if average <= 100*time.Millisecond || average >= time.Second {
+		t.Errorf("expected the average to blend both latencies, got %s", average)
+	}
+
+	observeCheckLatency(average * 2)
+
+	if got := latencyAnomalies.Load() - anomalies; got != 0 {
+		t.Errorf("expected a run within the multiplier not to count, got %d", got)
+	}
+
+	observeCheckLatency(10 * time.Second)
+
+	if got := latencyAnomalies.Load() - anomalies; got != 1 {
+		t.Errorf("expected one anomaly, got %d", got)
+	}
+}
+
+func TestObserveCheckLatencyDisabled(t *testing.T) {
+	setTestConfig(t, &Config{})
+	t.Cleanup(resetTestState)
+	resetTestState()
+
+	anomalies := latencyAnomalies.Load()
+
+	for range latencyEmaWarmup {
+		observeCheckLatency(time.Millisecond)
+	}
+
+	observeCheckLatency(time.Minute)
+
+	if got := latencyAnomalies.Load() - anomalies; got != 0 {
+		t.Errorf("expected no anomalies without a multiplier, got %d", got)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+var (
+	trustedProxies []netip.Prefix
+)
+
+/*
+parseTrustedProxies reads Config.TrustedProxies, a comma-separated list
+of IP addresses and CIDR ranges. Invalid entries are logged and skipped.
+*/
+func parseTrustedProxies(value string) []netip.Prefix {
+	var (
+		err    error
+		prefix netip.Prefix
+		addr   netip.Addr
+	)
+
+	result := []netip.Prefix{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			if prefix, err = netip.ParsePrefix(entry); err != nil {
+				slog.Error("ignoring invalid trusted proxy", "entry", entry, "error", err)
+				continue
+			}
+
+			result = append(result, prefix.Masked())
+			continue
+		}
+
+		if addr, err = netip.ParseAddr(entry); err != nil {
+			slog.Error("ignoring invalid trusted proxy", "entry", entry, "error", err)
+			continue
+		}
+
+		result = append(result, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return result
+}
+
+func isTrustedProxy(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+clientIP returns the address of the client that made the request. The
+X-Forwarded-For and X-Real-IP headers are only believed when the direct
+peer is a trusted proxy, since anyone else can set them. X-Forwarded-For
+is read from the right, skipping trusted proxies, so addresses a client
+prepends itself are ignored.
+*/
+func clientIP(r *http.Request) string {
+	var (
+		err  error
+		host string
+		peer netip.Addr
+		addr netip.Addr
+	)
+
+	if host, _, err = net.SplitHostPort(r.RemoteAddr); err != nil {
+		host = r.RemoteAddr
+	}
+
+	if peer, err = netip.ParseAddr(host); err != nil || !isTrustedProxy(peer) {
+		return host
+	}
+
+	if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		hops := strings.Split(forwardedFor, ",")
+
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+
+			if addr, err = netip.ParseAddr(hop); err != nil {
+				break
+			}
+
+			if !isTrustedProxy(addr) || i == 0 {
+				return addr.Unmap().String()
+			}
+		}
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		if addr, err = netip.ParseAddr(realIP); err == nil {
+			return addr.Unmap().String()
+		}
+	}
+
+	return host
+}
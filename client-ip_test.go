@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	got := parseTrustedProxies(" 10.0.0.0/8, 192.168.1.7,not-an-ip, ,10.1.2.3/33,::1")
+
+	want := []string{"10.0.0.0/8", "192.168.1.7/32", "::1/128"}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Errorf("expected %s, got %s", want[i], got[i])
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		realIP       string
+		want         string
+	}{
+		{name: "direct client", remoteAddr: "203.0.113.9:51234", want: "203.0.113.9"},
+		{name: "untrusted peer cannot spoof", remoteAddr: "203.0.113.9:51234", forwardedFor: "198.51.100.1", realIP: "198.51.100.2", want: "203.0.113.9"},
+		{name: "trusted proxy forwards client", remoteAddr: "10.0.0.2:443", forwardedFor: "198.51.100.1", want: "198.51.100.1"},
+		{name: "client prepended address is ignored", remoteAddr: "10.0.0.2:443", forwardedFor: "1.2.3.4, 198.51.100.1", want: "198.51.100.1"},
+		{name: "chain of trusted proxies is skipped", remoteAddr: "10.0.0.2:443", forwardedFor: "198.51.100.1, 10.0.0.5, 10.0.0.3", want: "198.51.100.1"},
+		{name: "all hops trusted uses the first", remoteAddr: "10.0.0.2:443", forwardedFor: "10.0.0.7, 10.0.0.3", want: "10.0.0.7"},
+		{name: "real IP from trusted proxy", remoteAddr: "10.0.0.2:443", realIP: "198.51.100.2", want: "198.51.100.2"},
+		{name: "invalid forwarded hop falls back to real IP", remoteAddr: "10.0.0.2:443", forwardedFor: "garbage", realIP: "198.51.100.2", want: "198.51.100.2"},
+		{name: "invalid headers fall back to peer", remoteAddr: "10.0.0.2:443", forwardedFor: "garbage", realIP: "garbage", want: "10.0.0.2"},
+		{name: "IPv4-mapped address is unmapped", remoteAddr: "[::ffff:10.0.0.2]:443", forwardedFor: "::ffff:198.51.100.1", want: "198.51.100.1"},
+	}
+
+	previousProxies := trustedProxies
+	t.Cleanup(func() { trustedProxies = previousProxies })
+	trustedProxies = parseTrustedProxies("10.0.0.0/8")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/status.rss", nil)
+			r.RemoteAddr = tt.remoteAddr
+
+			if tt.forwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			if got := clientIP(r); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
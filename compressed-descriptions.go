@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+compressedDescriptionPrefix marks a Feed.Description stored gzipped and
+base64 encoded. Rows without it are plain HTML, so turning
+Config.CompressDescriptions on or off leaves older rows readable.
+*/
+const compressedDescriptionPrefix = "gzip:"
+
+func compressDescription(description string) (string, error) {
+	var (
+		err error
+		b   = bytes.Buffer{}
+	)
+
+	writer := gzip.NewWriter(&b)
+
+	if _, err = writer.Write([]byte(description)); err != nil {
+		return "", err
+	}
+
+	if err = writer.Close(); err != nil {
+		return "", err
+	}
+
+	return compressedDescriptionPrefix + base64.StdEncoding.EncodeToString(b.Bytes()), nil
+}
+
+/*
+decompressDescription returns a stored description as HTML. Descriptions
+without the compressed prefix are returned unchanged.
+*/
+func decompressDescription(stored string) (string, error) {
+	var (
+		err    error
+		raw    []byte
+		reader *gzip.Reader
+		b      []byte
+	)
+
+	encoded, ok := strings.CutPrefix(stored, compressedDescriptionPrefix)
+
+	if !ok {
+		return stored, nil
+	}
+
+	if raw, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+		return "", fmt.Errorf("error decoding compressed description: %w", err)
+	}
+
+	if reader, err = gzip.NewReader(bytes.NewReader(raw)); err != nil {
+		return "", fmt.Errorf("error reading compressed description: %w", err)
+	}
+
+	defer reader.Close()
+
+	if b, err = io.ReadAll(reader); err != nil {
+		return "", fmt.Errorf("error decompressing description: %w", err)
+	}
+
+	return string(b), nil
+}
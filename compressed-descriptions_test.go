@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestCompressDescriptionRoundTrip(t *testing.T) {
+	for _, description := range []string{"", "<h2>All Services Operational</h2>", strings.Repeat("<li>Checkout</li>", 500)} {
+		compressed, err := compressDescription(description)
+
+		if err != nil {
+			t.Fatalf("error compressing description: %v", err)
+		}
+
+		if !strings.HasPrefix(compressed, compressedDescriptionPrefix) {
+			t.Errorf("expected the compressed prefix, got %q", compressed)
+		}
+
+		got, err := decompressDescription(compressed)
+
+		if err != nil {
+			t.Fatalf("error decompressing description: %v", err)
+		}
+
+		if got != description {
+			t.Errorf("expected %q after a round trip, got %q", description, got)
+		}
+	}
+}
+
+func TestDecompressDescription(t *testing.T) {
+	got, err := decompressDescription("<p>plain</p>")
+
+	if err != nil || got != "<p>plain</p>" {
+		t.Errorf("expected an uncompressed description unchanged, got %q, %v", got, err)
+	}
+
+	if _, err = decompressDescription(compressedDescriptionPrefix + "not base64!"); err == nil {
+		t.Errorf("expected an error for a corrupt description")
+	}
+}
+
+func TestCompressedDescriptionsAreReadBack(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if err := gorm.G[Feed](db).Create(ctx, &Feed{Title: "Older plain item", Description: "<p>plain</p>", PubDate: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatalf("error creating feed item: %v", err)
+	}
+
+	config.CompressDescriptions = true
+	runTestCheck(t, testPageOperational)
+
+	stored, err := gorm.G[Feed](db).Order("id desc").First(ctx)
+
+	if err != nil {
+		t.Fatalf("error querying stored item: %v", err)
+	}
+
+	if !strings.HasPrefix(stored.Description, compressedDescriptionPrefix) {
+		t.Errorf("expected the new description to be stored compressed, got %q", stored.Description)
+	}
+
+	feed := queryTestFeed(t)
+
+	if len(feed) != 2 || !strings.Contains(feed[0].Description, "<h2>") || feed[1].Description != "<p>plain</p>" {
+		t.Errorf("expected both descriptions as HTML, got %+v", feed)
+	}
+}
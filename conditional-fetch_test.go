@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestCronJobConditionalFetch(t *testing.T) {
+	var (
+		page          = testPageOutage
+		etag          = `"v1"`
+		ifNoneMatches []string
+	)
+
+	setupTestDB(t)
+	services, statuses := seedTestStatuses(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatches = append(ifNoneMatches, r.Header.Get("If-None-Match"))
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	config.StatusPageURL = server.URL
+
+	cronJob(services, statuses)
+	result := cronJob(services, statuses)
+
+	if result.Err != nil || result.Changed || !result.HasErrors {
+		t.Fatalf("expected an unchanged check still in error, got %+v", result)
+	}
+
+	if ifNoneMatches[1] != etag {
+		t.Errorf("expected the second fetch to send If-None-Match %s, got %q", etag, ifNoneMatches[1])
+	}
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	/*
+	 * A 304 still counts as a check, so history is recorded from the
+	 * stored snapshot.
+	 */
+	if count, err := gorm.G[ServiceStatus](db).Count(ctx, "*"); err != nil || count != 4 {
+		t.Errorf("expected history for both checks, got %d (%v)", count, err)
+	}
+
+	page, etag = testPageOperational, `"v2"`
+	result = cronJob(services, statuses)
+
+	if !result.Changed || result.HasErrors {
+		t.Fatalf("expected a recovery, got %+v", result)
+	}
+
+	lastStatus, err := queryLastStatus()
+
+	if err != nil {
+		t.Fatalf("error querying last status: %v", err)
+	}
+
+	if lastStatus.ETag != `"v2"` || lastStatus.LastStatusHash != generateStatusHash(ParsedStatusCollection{{Service: services[0], Status: statuses[0]}, {Service: services[1], Status: statuses[0]}}) {
+		t.Errorf("expected the new validators to be saved with the new hash, got %+v", lastStatus)
+	}
+
+	if feed := queryTestFeed(t); len(feed) != 2 {
+		t.Errorf("expected an error item and a recovery item, got %d", len(feed))
+	}
+}
+
+func TestGrabStatusPageFallsBackToLastModified(t *testing.T) {
+	var (
+		ifModifiedSince string
+	)
+
+	setTestConfig(t, &Config{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifModifiedSince = r.Header.Get("If-Modified-Since")
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	lastModified := "Wed, 21 Oct 2026 07:28:00 GMT"
+
+	if _, _, err := grabStatusPage(server.URL, PageValidators{LastModified: lastModified}); !errors.Is(err, ErrPageNotModified) {
+		t.Errorf("expected ErrPageNotModified, got %v", err)
+	}
+
+	if ifModifiedSince != lastModified {
+		t.Errorf("expected If-Modified-Since %s, got %q", lastModified, ifModifiedSince)
+	}
+}
+
+func TestCronJobIgnoresValidatorsWithoutSnapshot(t *testing.T) {
+	var (
+		ifNoneMatch string
+	)
+
+	setupTestDB(t)
+	services, statuses := seedTestStatuses(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = r.Header.Get("If-None-Match")
+
+		if ifNoneMatch == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, testPageOutage)
+	}))
+	defer server.Close()
+
+	config.StatusPageURL = server.URL
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	/*
+	 * A row from before snapshots were stored: the hash and validators of
+	 * an outage, but nothing to answer a 304 from.
+	 */
+	hash := generateStatusHash(ParsedStatusCollection{{Service: services[0], Status: statuses[0]}, {Service: services[1], Status: statuses[1]}})
+
+	if err := gorm.G[LastStatus](db).Create(ctx, &LastStatus{ID: 1, LastStatusHash: hash, ETag: `"v1"`}); err != nil {
+		t.Fatalf("error creating last status: %v", err)
+	}
+
+	result := cronJob(services, statuses)
+
+	if ifNoneMatch != "" {
+		t.Errorf("expected no If-None-Match without a snapshot, got %q", ifNoneMatch)
+	}
+
+	if result.Err != nil || result.Changed || !result.HasErrors {
+		t.Errorf("expected an unchanged check still in error, got %+v", result)
+	}
+
+	if feed := queryTestFeed(t); len(feed) != 0 {
+		t.Errorf("expected no feed items, got %+v", feed)
+	}
+}
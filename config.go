@@ -1,22 +1,134 @@
 package main
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/adampresley/configinator"
 	"github.com/adampresley/mux"
 )
 
 type Config struct {
 	mux.Config
-	AleticsURL    string `flag:"aleticsurl" env:"ALETICS_URL" default:"" description:"Aletics API URL"`
-	AleticsToken  string `flag:"aleticstoken" env:"ALETICS_TOKEN" default:"" description:"Aletics API Token"`
-	CronSchedule  string `flag:"cronschedule" env:"CRON_SCHEDULE" default:"*/30 * * * *" description:"cron schedule for status updates"`
-	DSN           string `flag:"dsn" env:"DSN" default:"file:./shopify-status-rss.db" description:"database connection string"`
-	LogLevel      string `flag:"loglevel" env:"LOG_LEVEL" default:"info" description:"slog log leve. defaults to info"`
-	StatusPageURL string `flag:"statuspageurl" env:"STATUS_PAGE_URL" default:"https://my.shopifystatus.com" description:"status page URL"`
+	AdminToken                 string        `flag:"admintoken" env:"ADMIN_TOKEN" default:"" description:"bearer token required for /admin endpoints. admin endpoints are disabled when empty"`
+	AffectedServicesHeader     bool          `flag:"affectedservicesheader" env:"AFFECTED_SERVICES_HEADER" default:"false" description:"set X-Affected-Services on feed responses to the number of services currently in error"`
+	AleticsURL                 string        `flag:"aleticsurl" env:"ALETICS_URL" default:"" description:"Aletics API URL"`
+	AleticsToken               string        `flag:"aleticstoken" env:"ALETICS_TOKEN" default:"" description:"Aletics API Token"`
+	AllowedRedirectHosts       string        `flag:"allowedredirecthosts" env:"ALLOWED_REDIRECT_HOSTS" default:"" description:"comma-separated hosts the status page may redirect to. a redirect to any other host fails the check"`
+	AsyncInitialCheck          bool          `flag:"asyncinitialcheck" env:"ASYNC_INITIAL_CHECK" default:"false" description:"run the startup status check in the background so the server starts immediately"`
+	AutoDisableMissingServices bool          `flag:"autodisablemissingservices" env:"AUTO_DISABLE_MISSING_SERVICES" default:"false" description:"disable a service missing from the status page for MISSING_SERVICE_RUNS checks instead of only writing a warning item"`
+	BufferFailedWrites         bool          `flag:"bufferfailedwrites" env:"BUFFER_FAILED_WRITES" default:"false" description:"hold status changes and feed items in memory when the database is unavailable and write them on the next check that reaches it"`
+	CacheFeedInDB              bool          `flag:"cachefeedindb" env:"CACHE_FEED_IN_DB" default:"false" description:"cache rendered feeds in the database so replicas share them"`
+	CompressDescriptions       bool          `flag:"compressdescriptions" env:"COMPRESS_DESCRIPTIONS" default:"false" description:"gzip feed item descriptions stored in the database. existing rows are read either way"`
+	CronSchedule               string        `flag:"cronschedule" env:"CRON_SCHEDULE" default:"*/30 * * * *" description:"cron schedule for status updates"`
+	DBWriteRetries             int           `flag:"dbwriteretries" env:"DB_WRITE_RETRIES" default:"3" description:"number of times to retry a database write that fails with a transient error"`
+	DBWriteRetryDelay          time.Duration `flag:"dbwriteretrydelay" env:"DB_WRITE_RETRY_DELAY" default:"200ms" description:"initial delay between database write retries. doubles on each attempt"`
+	DebugSelections            bool          `flag:"debugselections" env:"DEBUG_SELECTIONS" default:"false" description:"log every element matched by the service and icon selectors at debug level. requires LOG_LEVEL=debug"`
+	DedupeWindow               time.Duration `flag:"dedupewindow" env:"DEDUPE_WINDOW" default:"0s" description:"a feed item identical to one written within this window refreshes that item instead of adding another. 0 disables deduplication"`
+	DSN                        string        `flag:"dsn" env:"DSN" default:"file:./shopify-status-rss.db" description:"database connection string"`
+	DynamicChannelDescription  bool          `flag:"dynamicchanneldescription" env:"DYNAMIC_CHANNEL_DESCRIPTION" default:"false" description:"add the current overall state, such as 2 services degraded, to the RSS channel description"`
+	EmitNoDataItem             bool          `flag:"emitnodataitem" env:"EMIT_NO_DATA_ITEM" default:"false" description:"serve a placeholder item when the feed is empty"`
+	EscalationAfter            time.Duration `flag:"escalationafter" env:"ESCALATION_AFTER" default:"0s" description:"send one escalation notification when an outage is unresolved this long. 0 disables escalation"`
+	EscalationWebhookURL       string        `flag:"escalationwebhookurl" env:"ESCALATION_WEBHOOK_URL" default:"" description:"URL escalations are posted to as JSON. empty sends escalations to the regular notifiers"`
+	FailOnSelectorMismatch     bool          `flag:"failonselectormismatch" env:"FAIL_ON_SELECTOR_MISMATCH" default:"false" description:"exit at startup when the status page no longer matches the selectors"`
+	FeedAuthor                 string        `flag:"feedauthor" env:"FEED_AUTHOR" default:"" description:"author or source attributed in RSS (dc:creator), Atom, and JSON feeds"`
+	FeedCacheTTL               time.Duration `flag:"feedcachettl" env:"FEED_CACHE_TTL" default:"0s" description:"keep each rendered feed format in memory this long to serve request bursts. cleared when a feed item is written. 0 disables it"`
+	FeedRetention              time.Duration `flag:"feedretention" env:"FEED_RETENTION" default:"0s" description:"maintenance deletes feed items, status transitions, and service status history older than this. 0 keeps everything"`
+	FirstRunItem               string        `flag:"firstrunitem" env:"FIRST_RUN_ITEM" default:"current-state" description:"feed item written on the first check. current-state writes an error or operational item. baseline writes a neutral monitoring started item. none writes nothing"`
+	GroupSelector              string        `flag:"groupselector" env:"GROUP_SELECTOR" default:"" description:"selector for the status page sections that group services. the first heading inside is the group name. empty disables grouping"`
+	HashMode                   string        `flag:"hashmode" env:"HASH_MODE" default:"full" description:"full detects any change in service icons. error-only detects changes to the set of services in error. changing this causes one extra feed item"`
+	IdleTimeout                time.Duration `flag:"idletimeout" env:"IDLE_TIMEOUT" default:"2m" description:"how long the HTTP server keeps idle keep-alive connections open"`
+	IncidentLinkSelector       string        `flag:"incidentlinkselector" env:"INCIDENT_LINK_SELECTOR" default:"a[href*='/incidents/']" description:"selector for incident links on the status page"`
+	IncludeRawClassNames       bool          `flag:"includerawclassnames" env:"INCLUDE_RAW_CLASS_NAMES" default:"false" description:"include each service's matched icon class name as an HTML comment in feed descriptions"`
+	IncludeScrapeMetadata      bool          `flag:"includescrapemetadata" env:"INCLUDE_SCRAPE_METADATA" default:"false" description:"append the scrape time, fetch latency, and source URL to feed item descriptions as an HTML comment"`
+	IncludeStructuredServices  bool          `flag:"includestructuredservices" env:"INCLUDE_STRUCTURED_SERVICES" default:"false" description:"add an ssr:service element with each service's name and error state to RSS items so machine consumers need not parse the HTML"`
+	IncludeSummaryLine         bool          `flag:"includesummaryline" env:"INCLUDE_SUMMARY_LINE" default:"false" description:"start error and operational feed descriptions with a line such as 3 of 15 services affected"`
+	ItemGranularity            string        `flag:"itemgranularity" env:"ITEM_GRANULARITY" default:"combined" description:"combined writes one feed item per change. per-service writes one item per affected service"`
+	LatencyAnomalyMultiplier   float64       `flag:"latencyanomalymultiplier" env:"LATENCY_ANOMALY_MULTIPLIER" default:"0" description:"warn when a check's fetch and parse time exceeds its moving average by this multiple, such as 3. 0 disables the warning"`
+	LearnStatusesFromLegend    bool          `flag:"learnstatusesfromlegend" env:"LEARN_STATUSES_FROM_LEGEND" default:"false" description:"on startup, read the status page legend to add or correct status class names"`
+	LegendSelector             string        `flag:"legendselector" env:"LEGEND_SELECTOR" default:"div.legend i" description:"selector for the status icons in the status page legend"`
+	LockMaxAttempts            int           `flag:"lockmaxattempts" env:"LOCK_MAX_ATTEMPTS" default:"0" description:"maximum attempts to obtain a contended cron lock when LOCK_RETRY is set. 0 retries until LOCK_RETRY elapses"`
+	LockRetry                  time.Duration `flag:"lockretry" env:"LOCK_RETRY" default:"0s" description:"keep retrying a cron lock held by another run for this long instead of skipping the run. 0 skips immediately"`
+	LogLevel                   string        `flag:"loglevel" env:"LOG_LEVEL" default:"info" description:"slog log leve. defaults to info"`
+	MaintenanceSchedule        string        `flag:"maintenanceschedule" env:"MAINTENANCE_SCHEDULE" default:"0 3 * * *" description:"cron schedule for pruning and other maintenance. empty disables maintenance"`
+	MajorSeverityFraction      float64       `flag:"majorseverityfraction" env:"MAJOR_SEVERITY_FRACTION" default:"0.5" description:"fraction of services in error at which an outage is considered major"`
+	MaxDescriptionLength       int           `flag:"maxdescriptionlength" env:"MAX_DESCRIPTION_LENGTH" default:"0" description:"maximum length of a feed item description. longer service lists are truncated. 0 disables the limit"`
+	MaxPageBytes               int           `flag:"maxpagebytes" env:"MAX_PAGE_BYTES" default:"5242880" description:"maximum size in bytes of the status page body. 0 disables the limit"`
+	MaxStaleness               time.Duration `flag:"maxstaleness" env:"MAX_STALENESS" default:"0s" description:"/healthz reports unavailable when the last successful check is older than this. 0 disables the check"`
+	MigrateOnly                bool          `flag:"migrate-only" env:"MIGRATE_ONLY" default:"false" description:"run database migrations and exit without starting the server or cron"`
+	MinFeedSeverity            string        `flag:"minfeedseverity" env:"MIN_FEED_SEVERITY" default:"" description:"minimum outage severity (minor or major) written to the feed. lesser outages and their recoveries are skipped. empty writes everything. other values are rejected at startup"`
+	MismatchToleranceRuns      int           `flag:"mismatchtoleranceruns" env:"MISMATCH_TOLERANCE_RUNS" default:"0" description:"consecutive failed parses tolerated before a warning item is written"`
+	MissingServiceRuns         int           `flag:"missingserviceruns" env:"MISSING_SERVICE_RUNS" default:"0" description:"checks a service may be missing from the status page before a warning item is written or it is disabled. 0 disables tracking, unless AUTO_DISABLE_MISSING_SERVICES is set, where it means 3"`
+	NoAutoSeed                 bool          `flag:"noautoseed" env:"NO_AUTO_SEED" default:"false" description:"do not fill empty status and service tables from the built-in defaults at startup"`
+	NotifyWebhookURL           string        `flag:"notifywebhookurl" env:"NOTIFY_WEBHOOK_URL" default:"" description:"URL each new feed item is posted to as JSON. empty disables webhook notifications"`
+	PageUpdatedSelector        string        `flag:"pageupdatedselector" env:"PAGE_UPDATED_SELECTOR" default:"" description:"selector for the status page's own last updated time, reported by /healthz and /current.json. empty disables it"`
+	PageUpdatedTimezone        string        `flag:"pageupdatedtimezone" env:"PAGE_UPDATED_TIMEZONE" default:"UTC" description:"IANA time zone, such as America/New_York, of status page updated times written without an offset. zone abbreviations such as EST are only understood when they belong to this zone"`
+	PreferIncidentLinks        bool          `flag:"preferincidentlinks" env:"PREFER_INCIDENT_LINKS" default:"false" description:"link error feed items to the incident page when one is found on the status page"`
+	ReadDSN                    string        `flag:"readdsn" env:"READ_DSN" default:"" description:"connection string of a read replica used by the feed and report endpoints. writes always use DSN. empty reads from DSN"`
+	ReadTimeout                time.Duration `flag:"readtimeout" env:"READ_TIMEOUT" default:"1m" description:"maximum time the HTTP server waits to read a request, including its body"`
+	RenderServiceURL           string        `flag:"renderserviceurl" env:"RENDER_SERVICE_URL" default:"" description:"URL of a headless render service (e.g. browserless /content) used to fetch JavaScript-rendered status pages"`
+	RenderTimeout              time.Duration `flag:"rendertimeout" env:"RENDER_TIMEOUT" default:"30s" description:"how long to wait for RENDER_SERVICE_URL to return a rendered status page"`
+	RootContainerSelector      string        `flag:"rootcontainerselector" env:"ROOT_CONTAINER_SELECTOR" default:"" description:"selector for an element every real status page contains, such as div.flex-col. a page without it fails the check as empty. empty disables the check"`
+	S3AccessKey                string        `flag:"s3accesskey" env:"S3_ACCESS_KEY" default:"" description:"access key for publishing the feed to S3"`
+	S3Bucket                   string        `flag:"s3bucket" env:"S3_BUCKET" default:"" description:"bucket to publish the RSS feed to"`
+	S3Endpoint                 string        `flag:"s3endpoint" env:"S3_ENDPOINT" default:"" description:"S3-compatible endpoint to publish the RSS feed to whenever a feed item is written. empty disables publishing"`
+	S3Key                      string        `flag:"s3key" env:"S3_KEY" default:"status.rss" description:"object key for the published RSS feed"`
+	S3Region                   string        `flag:"s3region" env:"S3_REGION" default:"us-east-1" description:"region used to sign S3 requests"`
+	S3SecretKey                string        `flag:"s3secretkey" env:"S3_SECRET_KEY" default:"" description:"secret key for publishing the feed to S3"`
+	SkipDays                   string        `flag:"skipdays" env:"SKIP_DAYS" default:"" description:"comma-separated days (e.g. Saturday,Sunday) written to the RSS skipDays element so aggregators poll less"`
+	SkipHours                  string        `flag:"skiphours" env:"SKIP_HOURS" default:"" description:"comma-separated GMT hours (0-23) written to the RSS skipHours element so aggregators poll less"`
+	SLOTarget                  float64       `flag:"slotarget" env:"SLO_TARGET" default:"0.999" description:"availability target used by /slo.json to compute error budgets"`
+	StaleBannerAge             time.Duration `flag:"stalebannerage" env:"STALE_BANNER_AGE" default:"0s" description:"add a possibly-outdated banner item to feeds when the last successful status check is older than this. 0 disables the banner"`
+	StaleLockAge               time.Duration `flag:"stalelockage" env:"STALE_LOCK_AGE" default:"1h" description:"maintenance removes cron locks older than this, left behind by replicas that died mid-run. 0 disables the cleanup"`
+	StatusPageAuth             string        `flag:"statuspageauth" env:"STATUS_PAGE_AUTH" default:"" description:"credentials for a private status page, as basic:<user>:<password> or bearer:<token>. not sent through RENDER_SERVICE_URL"`
+	StatusPageURL              string        `flag:"statuspageurl" env:"STATUS_PAGE_URL" default:"https://my.shopifystatus.com" description:"status page URL"`
+	StillDownAfter             time.Duration `flag:"stilldownafter" env:"STILL_DOWN_AFTER" default:"0s" description:"write a follow-up feed item when an outage persists this long. 0 disables follow-ups"`
+	StrictStatusMatching       bool          `flag:"strictstatusmatching" env:"STRICT_STATUS_MATCHING" default:"false" description:"fail the check when a status icon matches no known status class and write a new status class detected item"`
+	TrustedProxies             string        `flag:"trustedproxies" env:"TRUSTED_PROXIES" default:"" description:"comma-separated IPs and CIDR ranges of proxies whose X-Forwarded-For and X-Real-IP headers are trusted for the client IP"`
+	UnknownStatusIsError       bool          `flag:"unknownstatusiserror" env:"UNKNOWN_STATUS_IS_ERROR" default:"false" description:"treat a status icon with no known class as an error rather than operational"`
+	UpstreamFeedURL            string        `flag:"upstreamfeedurl" env:"UPSTREAM_FEED_URL" default:"" description:"read service statuses from the RSS or Atom feed of another monitor instead of scraping the status page"`
+	UseContentEncoded          bool          `flag:"usecontentencoded" env:"USE_CONTENT_ENCODED" default:"false" description:"also write each RSS item's HTML as CDATA in content:encoded"`
+	VacuumAfterPrune           bool          `flag:"vacuumafterprune" env:"VACUUM_AFTER_PRUNE" default:"false" description:"on SQLite, run VACUUM and ANALYZE after maintenance prunes rows to reclaim disk space. runs at most once a day"`
+	ValidateFeedOutput         bool          `flag:"validatefeedoutput" env:"VALIDATE_FEED_OUTPUT" default:"false" description:"re-parse the rendered RSS feed before responding and return an error instead of serving malformed XML"`
+	WriteTimeout               time.Duration `flag:"writetimeout" env:"WRITE_TIMEOUT" default:"1m" description:"maximum time the HTTP server takes to write a response"`
 }
 
+/*
+defaultAutoDisableMissingRuns is the MissingServiceRuns used when
+AutoDisableMissingServices is on but no threshold is set, which would
+otherwise leave auto-disable doing nothing.
+*/
+const defaultAutoDisableMissingRuns = 3
+
 func LoadConfig() *Config {
 	result := &Config{}
 	configinator.Behold(result)
+	applyConfigDefaults(result)
 	return result
 }
+
+/*
+applyConfigDefaults fills in settings whose default depends on another
+setting and normalizes ones matched case-sensitively.
+*/
+func applyConfigDefaults(c *Config) {
+	if c.AutoDisableMissingServices && c.MissingServiceRuns <= 0 {
+		c.MissingServiceRuns = defaultAutoDisableMissingRuns
+	}
+
+	c.MinFeedSeverity = strings.ToLower(strings.TrimSpace(c.MinFeedSeverity))
+}
+
+/*
+validateConfig reports settings that would otherwise be silently
+ignored, such as a MinFeedSeverity that ranks below every outage and so
+filters nothing.
+*/
+func validateConfig(c *Config) error {
+	if c.MinFeedSeverity != "" && severityRanks[c.MinFeedSeverity] == 0 {
+		return fmt.Errorf("invalid MIN_FEED_SEVERITY '%s'. expected %s or %s", c.MinFeedSeverity, SeverityMinor, SeverityMajor)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestApplyConfigDefaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		wantMissing int
+	}{
+		{name: "tracking off", config: Config{}, wantMissing: 0},
+		{name: "auto-disable without threshold", config: Config{AutoDisableMissingServices: true}, wantMissing: defaultAutoDisableMissingRuns},
+		{name: "auto-disable with threshold", config: Config{AutoDisableMissingServices: true, MissingServiceRuns: 5}, wantMissing: 5},
+		{name: "warnings only", config: Config{MissingServiceRuns: 2}, wantMissing: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.config
+			applyConfigDefaults(&c)
+
+			if c.MissingServiceRuns != tt.wantMissing {
+				t.Errorf("expected MissingServiceRuns %d, got %d", tt.wantMissing, c.MissingServiceRuns)
+			}
+		})
+	}
+}
+
+func TestValidateConfigMinFeedSeverity(t *testing.T) {
+	tests := []struct {
+		name            string
+		minFeedSeverity string
+		want            string
+		wantErr         bool
+	}{
+		{name: "empty", minFeedSeverity: "", want: "", wantErr: false},
+		{name: "major", minFeedSeverity: "major", want: SeverityMajor, wantErr: false},
+		{name: "upper case", minFeedSeverity: " MAJOR ", want: SeverityMajor, wantErr: false},
+		{name: "unknown", minFeedSeverity: "critical", want: "critical", wantErr: true},
+		{name: "not an outage severity", minFeedSeverity: "info", want: SeverityInfo, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Config{MinFeedSeverity: tt.minFeedSeverity}
+			applyConfigDefaults(&c)
+
+			if c.MinFeedSeverity != tt.want {
+				t.Errorf("expected MinFeedSeverity %q, got %q", tt.want, c.MinFeedSeverity)
+			}
+
+			if err := validateConfig(&c); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
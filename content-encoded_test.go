@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRssFeedContentEncoded(t *testing.T) {
+	feed := []*Feed{{Title: "1 services reporting potential issues", Description: "<h2>Shopify Reports Issues</h2>", PubDate: time.Now()}}
+
+	setTestConfig(t, &Config{})
+
+	b, err := renderRssFeed(feed)
+
+	if err != nil {
+		t.Fatalf("error rendering feed: %v", err)
+	}
+
+	if strings.Contains(string(b), "content:encoded") || strings.Contains(string(b), "xmlns:content") {
+		t.Errorf("expected no content:encoded when disabled, got %s", b)
+	}
+
+	config.UseContentEncoded = true
+
+	if b, err = renderRssFeed(feed); err != nil {
+		t.Fatalf("error rendering feed: %v", err)
+	}
+
+	for _, want := range []string{
+		`xmlns:content="http://purl.org/rss/1.0/modules/content/"`,
+		`<content:encoded><![CDATA[<h2>Shopify Reports Issues</h2>]]></content:encoded>`,
+		`<description>&lt;h2&gt;Shopify Reports Issues&lt;/h2&gt;</description>`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("expected %s in %s", want, b)
+		}
+	}
+}
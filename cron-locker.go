@@ -4,19 +4,79 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+/*
+lockRetryInterval is how long Lock waits between attempts when
+PostgresLocker.RetryFor is set.
+*/
+const lockRetryInterval = time.Second
+
+/*
+cronLockContentions counts lock attempts that found the lock already
+held, reported by /healthz so operators can spot overlapping runs.
+*/
+var cronLockContentions atomic.Int64
+
+/*
+PostgresLocker keeps cron jobs from running on more than one replica at
+a time. When RetryFor is greater than zero, a lock held by another run is
+retried for up to that long instead of skipping the job straight away,
+so a check isn't missed while the previous one finishes. MaxAttempts
+caps the number of tries. 0 leaves only the RetryFor limit.
+*/
 type PostgresLocker struct {
-	DB *gorm.DB
+	DB          *gorm.DB
+	RetryFor    time.Duration
+	MaxAttempts int
 }
 
 func (l *PostgresLocker) Lock(ctx context.Context, key string) error {
+	var (
+		err error
+	)
+
+	start := time.Now()
+	deadline := start.Add(l.RetryFor)
+	attempts := 0
+
+	for {
+		attempts++
+
+		if err = l.tryLock(ctx, key); !errors.Is(err, ErrCronLockInUse) {
+			if err == nil && attempts > 1 {
+				slog.Info("obtained contended cron lock", "key", key, "attempts", attempts, "waited", time.Since(start))
+			}
+
+			return err
+		}
+
+		cronLockContentions.Add(1)
+
+		if !time.Now().Before(deadline) || (l.MaxAttempts > 0 && attempts >= l.MaxAttempts) {
+			slog.Warn("cron lock is held by another run. giving up", "key", key, "attempts", attempts, "waited", time.Since(start))
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Warn("cron lock is held by another run. giving up", "key", key, "attempts", attempts, "waited", time.Since(start))
+			return err
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+func (l *PostgresLocker) tryLock(ctx context.Context, key string) error {
 	_, err := gorm.G[*CronLock](db).Where("key=?", key).First(ctx)
 
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return fmt.Errorf("cannot obtain cron lock. key '%s' already in use", key)
+		return fmt.Errorf("%w. key '%s' already in use", ErrCronLockInUse, key)
 	}
 
 	newRecord := &CronLock{Key: key}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostgresLockerRetriesUntilLockFrees(t *testing.T) {
+	setupTestDB(t)
+
+	holder := &PostgresLocker{DB: db}
+	ctx := context.Background()
+
+	if err := holder.Lock(ctx, checkStatusLockKey); err != nil {
+		t.Fatalf("error taking lock: %v", err)
+	}
+
+	go func() {
+		time.Sleep(lockRetryInterval / 2)
+		_ = holder.Unlock(ctx, checkStatusLockKey)
+	}()
+
+	locker := &PostgresLocker{DB: db, RetryFor: 3 * lockRetryInterval}
+
+	if err := locker.Lock(ctx, checkStatusLockKey); err != nil {
+		t.Fatalf("expected the lock once the previous run finished, got %v", err)
+	}
+
+	if err := locker.Unlock(ctx, checkStatusLockKey); err != nil {
+		t.Errorf("error releasing lock: %v", err)
+	}
+}
+
+func TestPostgresLockerWithoutRetrySkips(t *testing.T) {
+	setupTestDB(t)
+
+	ctx := context.Background()
+	holder := &PostgresLocker{DB: db}
+
+	if err := holder.Lock(ctx, checkStatusLockKey); err != nil {
+		t.Fatalf("error taking lock: %v", err)
+	}
+
+	start := time.Now()
+
+	if err := (&PostgresLocker{DB: db}).Lock(ctx, checkStatusLockKey); !errors.Is(err, ErrCronLockInUse) {
+		t.Errorf("expected ErrCronLockInUse, got %v", err)
+	}
+
+	if waited := time.Since(start); waited >= lockRetryInterval {
+		t.Errorf("expected no retry without RetryFor, waited %s", waited)
+	}
+}
+
+func TestPostgresLockerGivesUpAfterMaxAttempts(t *testing.T) {
+	var (
+		logs bytes.Buffer
+	)
+
+	setupTestDB(t)
+
+	previousLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previousLogger) })
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	ctx := context.Background()
+
+	if err := (&PostgresLocker{DB: db}).Lock(ctx, checkStatusLockKey); err != nil {
+		t.Fatalf("error taking lock: %v", err)
+	}
+
+	contentions := cronLockContentions.Load()
+	locker := &PostgresLocker{DB: db, RetryFor: time.Minute, MaxAttempts: 2}
+
+	if err := locker.Lock(ctx, checkStatusLockKey); !errors.Is(err, ErrCronLockInUse) {
+		t.Fatalf("expected ErrCronLockInUse, got %v", err)
+	}
+
+	if got := cronLockContentions.Load() - contentions; got != 2 {
+		t.Errorf("expected 2 contentions counted, got %d", got)
+	}
+
+	if !strings.Contains(logs.String(), "cron lock is held by another run. giving up") || !strings.Contains(logs.String(), "attempts=2") {
+		t.Errorf("expected a give up log with the attempt count, got %s", logs.String())
+	}
+
+	if health, _ := getTestHealth(t); health.LockContentions != cronLockContentions.Load() {
+		t.Errorf("expected /healthz to report %d contentions, got %d", cronLockContentions.Load(), health.LockContentions)
+	}
+}
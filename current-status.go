@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/adampresley/httphelpers/responses"
+	"gorm.io/gorm"
+)
+
+type CurrentStatusResponse struct {
+	CheckedAt     time.Time      `json:"checkedAt"`
+	ChangedAt     time.Time      `json:"changedAt"`
+	PageUpdatedAt *time.Time     `json:"pageUpdatedAt,omitempty"`
+	HasErrors     bool           `json:"hasErrors"`
+	Services      []ServiceState `json:"services"`
+}
+
+/*
+currentJsonHandler returns the service states recorded by the most recent
+successful check.
+*/
+func currentJsonHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err        error
+			lastStatus *LastStatus
+		)
+
+		if lastStatus, err = queryLastStatus(); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				responses.JsonErrorMessage(w, http.StatusNotFound, "No status has been recorded yet")
+				return
+			}
+
+			slog.Error("error querying last status", "error", err)
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, "An unexpected error occurred while querying the current status")
+			return
+		}
+
+		result := CurrentStatusResponse{
+			CheckedAt: lastStatus.LastSuccessAt,
+			ChangedAt: lastStatus.ChangedAt,
+			Services:  parseSnapshot(lastStatus.Snapshot),
+		}
+
+		if !lastStatus.PageUpdatedAt.IsZero() {
+			result.PageUpdatedAt = &lastStatus.PageUpdatedAt
+		}
+
+		for _, service := range result.Services {
+			if service.IsError {
+				result.HasErrors = true
+				break
+			}
+		}
+
+		writeJsonOK(w, r, result)
+	}
+}
+
+/*
+debugParseHandler fetches and parses the status page on demand and returns
+the parsed collection without recording anything.
+*/
+func debugParseHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err      error
+			doc      *goquery.Document
+			services []*Service
+			statuses []*Status
+			states   ParsedStatusCollection
+		)
+
+		if services, err = queryServices(); err != nil {
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if statuses, err = queryStatuses(); err != nil {
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if doc, _, err = grabStatusPage(config.StatusPageURL, PageValidators{}); err != nil {
+			responses.JsonErrorMessage(w, http.StatusBadGateway, err.Error())
+			return
+		}
+
+		if states, err = parsePageStatuses(doc, services, statuses); err != nil {
+			responses.JsonErrorMessage(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
+		writeJsonOK(w, r, states)
+	}
+}
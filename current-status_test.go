@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCurrentJsonHandler(t *testing.T) {
+	var (
+		current CurrentStatusResponse
+	)
+
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	w := httptest.NewRecorder()
+	currentJsonHandler()(w, httptest.NewRequest(http.MethodGet, "/current.json", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 before the first check, got %d", w.Code)
+	}
+
+	runTestCheck(t, testPageOutage)
+
+	w = httptest.NewRecorder()
+	currentJsonHandler()(w, httptest.NewRequest(http.MethodGet, "/current.json", nil))
+
+	if err := json.Unmarshal(w.Body.Bytes(), &current); err != nil {
+		t.Fatalf("error decoding current status: %v", err)
+	}
+
+	want := []ServiceState{
+		{ServiceName: "Checkout", Status: "Operational", ClassName: "ok"},
+		{ServiceName: "Storefront", Status: "Outage", ClassName: "down", IsError: true},
+	}
+
+	if !current.HasErrors || len(current.Services) != len(want) {
+		t.Fatalf("unexpected current status %+v", current)
+	}
+
+	for i, state := range current.Services {
+		if state != want[i] {
+			t.Errorf("expected %+v, got %+v", want[i], state)
+		}
+	}
+}
+
+func TestDebugParseHandlerSharesTheSnapshotShape(t *testing.T) {
+	var (
+		states []ServiceState
+	)
+
+	setupTestDB(t)
+	seedTestStatuses(t)
+	serveStatusPage(t, testPageOutage)
+
+	w := httptest.NewRecorder()
+	debugParseHandler()(w, httptest.NewRequest(http.MethodGet, "/debug/parse", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if err := json.Unmarshal(w.Body.Bytes(), &states); err != nil {
+		t.Fatalf("error decoding parsed states: %v", err)
+	}
+
+	if len(states) != 2 || states[1].ServiceName != "Storefront" || !states[1].IsError || states[1].ClassName != "down" {
+		t.Errorf("unexpected parsed states %+v", states)
+	}
+
+	if _, err := queryLastStatus(); err == nil {
+		t.Errorf("expected nothing to be recorded")
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+/*
+Error fragments reported by SQLite and Postgres when a write fails for
+a reason that is likely to clear up on its own.
+*/
+var retriableDBErrors = []string{
+	"database is locked",
+	"database table is locked",
+	"sqlite_busy",
+	"deadlock detected",
+	"could not serialize access",
+	"(sqlstate 40001)",
+	"(sqlstate 40p01)",
+}
+
+/*
+withWriteRetry runs a database write, retrying with exponential backoff
+when it fails with a transient error such as a SQLite lock or a Postgres
+deadlock. Any other error is returned immediately.
+*/
+func withWriteRetry(operation string, fn func() error) error {
+	var (
+		err error
+	)
+
+	delay := config.DBWriteRetryDelay
+
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+
+		if !isRetriableDBError(err) || attempt >= config.DBWriteRetries {
+			break
+		}
+
+		slog.Warn("transient database error. retrying write", "operation", operation, "attempt", attempt+1, "delay", delay, "error", err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	return fmt.Errorf("error performing database write '%s': %w", operation, err)
+}
+
+func isRetriableDBError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	message := strings.ToLower(err.Error())
+
+	for _, fragment := range retriableDBErrors {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithWriteRetry(t *testing.T) {
+	locked := errors.New("database is locked (5) (SQLITE_BUSY)")
+	constraint := errors.New("UNIQUE constraint failed: services.service_name")
+
+	tests := []struct {
+		name         string
+		failures     []error
+		retries      int
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "succeeds first time", failures: nil, retries: 3, wantAttempts: 1},
+		{name: "recovers from a lock", failures: []error{locked, locked}, retries: 3, wantAttempts: 3},
+		{name: "gives up after retries", failures: []error{locked, locked, locked}, retries: 2, wantAttempts: 3, wantErr: locked},
+		{name: "does not retry other errors", failures: []error{constraint}, retries: 3, wantAttempts: 1, wantErr: constraint},
+		{name: "retries disabled", failures: []error{locked}, retries: 0, wantAttempts: 1, wantErr: locked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, &Config{DBWriteRetries: tt.retries})
+
+			attempts := 0
+
+			err := withWriteRetry("test", func() error {
+				attempts++
+
+				if attempts <= len(tt.failures) {
+					return tt.failures[attempts-1]
+				}
+
+				return nil
+			})
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, attempts)
+			}
+
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestIsRetriableDBError(t *testing.T) {
+	tests := map[string]bool{
+		"ERROR: deadlock detected (SQLSTATE 40P01)":                  true,
+		"ERROR: could not serialize access due to concurrent update": true,
+		"database table is locked":                                   true,
+		"record not found":                                           false,
+	}
+
+	for message, want := range tests {
+		if got := isRetriableDBError(errors.New(message)); got != want {
+			t.Errorf("%q: expected %v, got %v", message, want, got)
+		}
+	}
+
+	if isRetriableDBError(nil) {
+		t.Errorf("expected nil not to be retriable")
+	}
+}
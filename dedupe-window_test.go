@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDedupeWindow(t *testing.T) {
+	tests := []struct {
+		name         string
+		dedupeWindow time.Duration
+		wantItems    int
+	}{
+		{name: "disabled", dedupeWindow: 0, wantItems: 4},
+		{name: "within window", dedupeWindow: time.Hour, wantItems: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			services, statuses := seedTestStatuses(t)
+
+			config.DedupeWindow = tt.dedupeWindow
+
+			/*
+			 * One server for every check, so the items' links match.
+			 */
+			page := testPageOutage
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, page)
+			}))
+
+			t.Cleanup(server.Close)
+			config.StatusPageURL = server.URL
+
+			for _, next := range []string{testPageOutage, testPageOperational, testPageOutage, testPageOperational} {
+				page = next
+				cronJob(services, statuses)
+			}
+
+			feed := queryTestFeed(t)
+
+			if len(feed) != tt.wantItems {
+				t.Fatalf("expected %d items, got %d", tt.wantItems, len(feed))
+			}
+
+			/*
+			 * A refreshed item moves back to the top of the feed.
+			 */
+			if feed[0].Title != "All services appear to be operational" {
+				t.Errorf("expected the latest transition first, got %q", feed[0].Title)
+			}
+		})
+	}
+}
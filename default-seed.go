@@ -0,0 +1,162 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+defaultSeedJson holds the same statuses and services as
+sql/seed-data.sql, so a fresh database works without running the SQL by
+hand.
+*/
+//go:embed sql/default-seed.json
+var defaultSeedJson []byte
+
+type DefaultSeed struct {
+	Statuses []Status  `json:"statuses"`
+	Services []Service `json:"services"`
+}
+
+/*
+seedDefaults fills the Status and Service tables from the embedded
+defaults when they are empty. Tables that already have rows are left
+alone, so edits made by operators are never overwritten.
+*/
+func seedDefaults() error {
+	return seedDefaultRows(false)
+}
+
+/*
+runSeed implements the "seed" subcommand. It adds every default status
+and service missing by class name or service name, leaving existing rows
+untouched, so it can be run any number of times. It returns the process
+exit code.
+*/
+func runSeed() int {
+	if err := seedDefaultRows(true); err != nil {
+		slog.Error("error seeding default statuses and services", "error", err)
+		return 1
+	}
+
+	return 0
+}
+
+/*
+seedDefaultRows inserts the embedded defaults, skipping any whose natural
+key already exists. Unless all is set, a table that already has rows is
+not touched.
+*/
+func seedDefaultRows(all bool) error {
+	var (
+		err   error
+		seed  DefaultSeed
+		count int64
+	)
+
+	if err = json.Unmarshal(defaultSeedJson, &seed); err != nil {
+		return fmt.Errorf("error decoding embedded default seed: %w", err)
+	}
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if count, err = gorm.G[Status](db).Count(ctx, "*"); err != nil {
+		return fmt.Errorf("error counting statuses: %w", err)
+	}
+
+	if all || count == 0 {
+		if err = gorm.G[Status](db, clause.OnConflict{Columns: []clause.Column{{Name: "class_name"}}, DoNothing: true}).CreateInBatches(ctx, &seed.Statuses, 100); err != nil {
+			return fmt.Errorf("error seeding statuses: %w", err)
+		}
+
+		slog.Info("seeded default statuses", "defaults", len(seed.Statuses))
+	}
+
+	if count, err = gorm.G[Service](db).Count(ctx, "*"); err != nil {
+		return fmt.Errorf("error counting services: %w", err)
+	}
+
+	if all || count == 0 {
+		if err = gorm.G[Service](db, clause.OnConflict{Columns: []clause.Column{{Name: "service_name"}}, DoNothing: true}).CreateInBatches(ctx, &seed.Services, 100); err != nil {
+			return fmt.Errorf("error seeding services: %w", err)
+		}
+
+		slog.Info("seeded default services", "defaults", len(seed.Services))
+	}
+
+	return nil
+}
+
+/*
+naturalKeys lists the tables whose natural key is unique, the key column,
+and the service_statuses column that references them.
+*/
+var naturalKeys = []struct {
+	table     string
+	column    string
+	reference string
+}{
+	{table: "services", column: "service_name", reference: "service_id"},
+	{table: "statuses", column: "class_name", reference: "status_id"},
+}
+
+/*
+dedupeNaturalKeys removes duplicate services and statuses, such as ones
+left by running the seed script more than once before the natural keys
+were unique. AutoMigrate cannot add the unique indexes while duplicates
+exist. The oldest live row for each key is kept and history pointing at
+a duplicate is moved to it. Soft-deleted rows count, as the index covers
+them too, but one is only kept when the key has no live row, so a
+service that is still checked never loses to a deleted copy.
+*/
+func dedupeNaturalKeys() error {
+	var (
+		err        error
+		duplicates []int64
+	)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	for _, key := range naturalKeys {
+		if !db.Migrator().HasTable(key.table) {
+			continue
+		}
+
+		keptID := "COALESCE(MIN(CASE WHEN %[1]s.deleted_at IS NULL THEN %[1]s.id END), MIN(%[1]s.id))"
+		kept := fmt.Sprintf("SELECT "+keptID+" FROM %[1]s GROUP BY %[2]s", key.table, key.column)
+
+		if duplicates, err = gorm.G[int64](db).Raw(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE id NOT IN (%s)", key.table, kept)).Find(ctx); err != nil {
+			return fmt.Errorf("error counting duplicate %s: %w", key.table, err)
+		}
+
+		if len(duplicates) == 0 || duplicates[0] == 0 {
+			continue
+		}
+
+		if db.Migrator().HasTable("service_statuses") {
+			repoint := fmt.Sprintf(
+				"UPDATE service_statuses SET %[3]s = (SELECT %[5]s FROM %[1]s k JOIN %[1]s d ON d.%[2]s = k.%[2]s WHERE d.id = service_statuses.%[3]s) WHERE %[3]s IN (SELECT id FROM %[1]s WHERE id NOT IN (%[4]s))",
+				key.table, key.column, key.reference, kept, fmt.Sprintf(keptID, "k"),
+			)
+
+			if err = gorm.G[any](db).Exec(ctx, repoint); err != nil {
+				return fmt.Errorf("error moving history off duplicate %s: %w", key.table, err)
+			}
+		}
+
+		if err = gorm.G[any](db).Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE id NOT IN (%s)", key.table, kept)); err != nil {
+			return fmt.Errorf("error removing duplicate %s: %w", key.table, err)
+		}
+
+		slog.Warn("removed duplicate rows before adding a unique index", "table", key.table, "column", key.column, "count", duplicates[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestRunSeedIsIdempotent(t *testing.T) {
+	var (
+		err               error
+		seed              DefaultSeed
+		services          []Service
+		statuses, renamed []Status
+	)
+
+	setupTestDB(t)
+
+	for range 2 {
+		if code := runSeed(); code != 0 {
+			t.Fatalf("expected seed to succeed, got exit code %d", code)
+		}
+	}
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if services, err = gorm.G[Service](db).Find(ctx); err != nil {
+		t.Fatalf("error querying services: %v", err)
+	}
+
+	if statuses, err = gorm.G[Status](db).Find(ctx); err != nil {
+		t.Fatalf("error querying statuses: %v", err)
+	}
+
+	if err = json.Unmarshal(defaultSeedJson, &seed); err != nil {
+		t.Fatalf("error decoding seed: %v", err)
+	}
+
+	if len(services) != len(seed.Services) || len(statuses) != len(seed.Statuses) {
+		t.Errorf("expected %d services and %d statuses, got %d and %d", len(seed.Services), len(seed.Statuses), len(services), len(statuses))
+	}
+
+	/*
+	 * A status an operator renamed keeps its name when the seed runs again.
+	 */
+	if _, err = gorm.G[Status](db).Where("class_name=?", seed.Statuses[0].ClassName).Update(ctx, "status", "Renamed"); err != nil {
+		t.Fatalf("error renaming status: %v", err)
+	}
+
+	if code := runSeed(); code != 0 {
+		t.Fatalf("expected seed to succeed, got exit code %d", code)
+	}
+
+	if renamed, err = gorm.G[Status](db).Where("status=?", "Renamed").Find(ctx); err != nil || len(renamed) != 1 {
+		t.Errorf("expected the renamed status to be kept, got %v %v", renamed, err)
+	}
+}
+
+func TestRunMigrationsRemovesDuplicateNaturalKeys(t *testing.T) {
+	var (
+		err      error
+		services []Service
+		history  []ServiceStatus
+	)
+
+	connectTestDB(t)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	/*
+	 * The schema as it was before service names and class names were
+	 * unique, with the seed script run twice.
+	 */
+	for _, statement := range []string{
+		"CREATE TABLE services (id integer PRIMARY KEY AUTOINCREMENT, created_at datetime, updated_at datetime, deleted_at datetime, service_name text, active numeric DEFAULT true, missing_runs integer)",
+		"CREATE TABLE statuses (id integer PRIMARY KEY AUTOINCREMENT, created_at datetime, updated_at datetime, deleted_at datetime, status text, class_name text, is_error numeric)",
+		"CREATE TABLE service_statuses (id integer PRIMARY KEY AUTOINCREMENT, created_at datetime, updated_at datetime, deleted_at datetime, status_id integer, service_id integer)",
+		"INSERT INTO services (service_name) VALUES ('Checkout'), ('Storefront'), ('Checkout')",
+		"INSERT INTO statuses (status, class_name, is_error) VALUES ('Operational', 'ok', false), ('Operational', 'ok', false)",
+		"INSERT INTO service_statuses (service_id, status_id) VALUES (1, 1), (3, 2)",
+	} {
+		if err = gorm.G[any](db).Exec(ctx, statement); err != nil {
+			t.Fatalf("error building old schema: %v", err)
+		}
+	}
+
+	if err = runMigrations(); err != nil {
+		t.Fatalf("expected migrations to succeed, got %v", err)
+	}
+
+	if services, err = gorm.G[Service](db).Order("id").Find(ctx); err != nil {
+		t.Fatalf("error querying services: %v", err)
+	}
+
+	if len(services) != 2 || services[0].ID != 1 || services[1].ID != 2 {
+		t.Errorf("expected the oldest Checkout and Storefront to remain, got %+v", services)
+	}
+
+	if history, err = gorm.G[ServiceStatus](db).Order("id").Find(ctx); err != nil {
+		t.Fatalf("error querying service statuses: %v", err)
+	}
+
+	for _, h := range history {
+		if h.ServiceID != 1 || h.StatusID != 1 {
+			t.Errorf("expected history to point at the kept rows, got service %d status %d", h.ServiceID, h.StatusID)
+		}
+	}
+
+	if err = gorm.G[Service](db).Create(ctx, &Service{ServiceName: "Checkout"}); err == nil {
+		t.Errorf("expected the unique index to reject another Checkout")
+	}
+}
+
+func TestRunMigrationsKeepsLiveRowOverSoftDeletedDuplicate(t *testing.T) {
+	var (
+		err      error
+		services []*Service
+		history  []ServiceStatus
+	)
+
+	connectTestDB(t)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	/*
+	 * The older Checkout was soft-deleted and re-added, so the live row
+	 * has the higher id.
+	 */
+	for _, statement := range []string{
+		"CREATE TABLE services (id integer PRIMARY KEY AUTOINCREMENT, created_at datetime, updated_at datetime, deleted_at datetime, service_name text, active numeric DEFAULT true, missing_runs integer)",
+		"CREATE TABLE service_statuses (id integer PRIMARY KEY AUTOINCREMENT, created_at datetime, updated_at datetime, deleted_at datetime, status_id integer, service_id integer)",
+		"INSERT INTO services (service_name, deleted_at) VALUES ('Checkout', CURRENT_TIMESTAMP), ('Checkout', NULL), ('Storefront', CURRENT_TIMESTAMP)",
+		"INSERT INTO service_statuses (service_id, status_id) VALUES (1, 1), (2, 1)",
+	} {
+		if err = gorm.G[any](db).Exec(ctx, statement); err != nil {
+			t.Fatalf("error building old schema: %v", err)
+		}
+	}
+
+	if err = runMigrations(); err != nil {
+		t.Fatalf("expected migrations to succeed, got %v", err)
+	}
+
+	if services, err = queryServices(); err != nil {
+		t.Fatalf("error querying services: %v", err)
+	}
+
+	if len(services) != 1 || services[0].ID != 2 {
+		t.Errorf("expected the live Checkout to remain, got %+v", services)
+	}
+
+	if history, err = gorm.G[ServiceStatus](db).Find(ctx); err != nil {
+		t.Fatalf("error querying service statuses: %v", err)
+	}
+
+	for _, h := range history {
+		if h.ServiceID != 2 {
+			t.Errorf("expected history to point at the live row, got service %d", h.ServiceID)
+		}
+	}
+
+	/*
+	 * A key with only soft-deleted rows keeps one of them.
+	 */
+	if count, err := gorm.G[Service](db).Scopes(unscoped).Where("service_name = ?", "Storefront").Count(ctx, "*"); err != nil || count != 1 {
+		t.Errorf("expected the soft-deleted Storefront to remain, got %d (%v)", count, err)
+	}
+}
+
+func TestSeedDefaults(t *testing.T) {
+	var (
+		seed DefaultSeed
+	)
+
+	setupTestDB(t)
+
+	if err := json.Unmarshal(defaultSeedJson, &seed); err != nil {
+		t.Fatalf("error decoding seed: %v", err)
+	}
+
+	if err := seedDefaults(); err != nil {
+		t.Fatalf("error seeding defaults: %v", err)
+	}
+
+	services, err := queryServices()
+
+	if err != nil {
+		t.Fatalf("error querying services: %v", err)
+	}
+
+	statuses, err := queryStatuses()
+
+	if err != nil {
+		t.Fatalf("error querying statuses: %v", err)
+	}
+
+	if len(services) != len(seed.Services) || len(statuses) != len(seed.Statuses) {
+		t.Errorf("expected an empty database to be seeded, got %d services and %d statuses", len(services), len(statuses))
+	}
+}
+
+func TestSeedDefaultsLeavesPopulatedTablesAlone(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	if err := seedDefaults(); err != nil {
+		t.Fatalf("error seeding defaults: %v", err)
+	}
+
+	services, err := queryServices()
+
+	if err != nil {
+		t.Fatalf("error querying services: %v", err)
+	}
+
+	statuses, err := queryStatuses()
+
+	if err != nil {
+		t.Fatalf("error querying statuses: %v", err)
+	}
+
+	if len(services) != 2 || len(statuses) != 2 {
+		t.Errorf("expected existing rows to be left alone, got %d services and %d statuses", len(services), len(statuses))
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+/*
+checkEscalation sends one escalation notification when an outage, as
+tracked by trackOutage, has lasted Config.EscalationAfter. Escalations go
+to the escalation notifiers, or to the regular notifiers when none are
+configured. Outages below Config.MinFeedSeverity are not escalated, as
+they are kept out of the feed too. The escalation only counts as sent
+once a notifier accepts it, so one that every notifier failed is retried
+on the next check.
+*/
+func checkEscalation(lastStatus *LastStatus, states ParsedStatusCollection) {
+	var (
+		err error
+	)
+
+	if config.EscalationAfter <= 0 || !states.HasErrors() || !meetsMinFeedSeverity(nil, states) {
+		return
+	}
+
+	downFor := outageDuration(lastStatus)
+
+	if lastStatus.EscalationSent || downFor < config.EscalationAfter {
+		return
+	}
+
+	targets := escalationNotifiers
+
+	if len(targets) == 0 {
+		targets = notifiers
+	}
+
+	if len(targets) == 0 {
+		return
+	}
+
+	slog.Warn("outage unresolved beyond escalation threshold. escalating", "downFor", downFor, "threshold", config.EscalationAfter)
+
+	item := generateStillDownFeedItem(states, downFor)
+	item.Title = fmt.Sprintf("Escalation: %s", item.Title)
+
+	delivered := false
+
+	for _, notifier := range targets {
+		ctx, cancel := getContext()
+
+		if err = notifier.Notify(ctx, item, item.Severity); err != nil {
+			slog.Error("error sending escalation", "notifier", notifier.Name(), "error", err)
+		} else {
+			delivered = true
+		}
+
+		cancel()
+	}
+
+	if !delivered {
+		return
+	}
+
+	if err = markEscalationSent(); err != nil {
+		slog.Error("error marking escalation as sent", "error", err)
+	}
+}
+
+func markEscalationSent() error {
+	return withWriteRetry("mark escalation sent", func() error {
+		ctx, cancel := getContext()
+		defer cancel()
+
+		_, err := gorm.G[LastStatus](db).Where("id=1").Update(ctx, "escalation_sent", true)
+		return err
+	})
+}
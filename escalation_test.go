@@ -0,0 +1,165 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func backdateTestOutage(t *testing.T, startedAt time.Time) {
+	t.Helper()
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if _, err := gorm.G[LastStatus](db).Where("id=1").Update(ctx, "outage_started_at", startedAt); err != nil {
+		t.Fatalf("error backdating outage: %v", err)
+	}
+}
+
+func countEscalations(payloads []WebhookPayload) int {
+	result := 0
+
+	for _, payload := range payloads {
+		if strings.HasPrefix(payload.Title, "Escalation: ") {
+			result++
+		}
+	}
+
+	return result
+}
+
+func TestEscalationIsSentOncePerOutage(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	escalationServer, escalations := serveWebhook(t, http.StatusOK)
+	notifyServer, notifications := serveWebhook(t, http.StatusOK)
+
+	config.EscalationAfter = time.Hour
+	config.EscalationWebhookURL = escalationServer.URL
+	config.NotifyWebhookURL = notifyServer.URL
+	notifiers = buildNotifiers(config)
+	escalationNotifiers = buildEscalationNotifiers(config)
+
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, testPageOutage)
+	runTestCheck(t, testPageOutage)
+
+	if got := countEscalations(escalations.Payloads()); got != 0 {
+		t.Fatalf("expected no escalation before the threshold, got %d", got)
+	}
+
+	backdateTestOutage(t, time.Now().Add(-2*time.Hour))
+	runTestCheck(t, testPageOutage)
+	runTestCheck(t, testPageOutage)
+
+	payloads := escalations.Payloads()
+
+	if countEscalations(payloads) != 1 || !strings.Contains(payloads[0].Title, "Still down after 2 hours") {
+		t.Errorf("expected one escalation, got %+v", payloads)
+	}
+
+	if got := countEscalations(notifications.Payloads()); got != 0 {
+		t.Errorf("expected escalations to skip the regular notifiers, got %d", got)
+	}
+
+	/*
+	 * A new outage can be escalated again.
+	 */
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, testPageOutage)
+	backdateTestOutage(t, time.Now().Add(-2*time.Hour))
+	runTestCheck(t, testPageOutage)
+
+	if got := countEscalations(escalations.Payloads()); got != 2 {
+		t.Errorf("expected the second outage to be escalated, got %d escalations", got)
+	}
+}
+
+func TestEscalationFallsBackToNotifiers(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	notifyServer, notifications := serveWebhook(t, http.StatusOK)
+
+	config.EscalationAfter = time.Hour
+	config.NotifyWebhookURL = notifyServer.URL
+	notifiers = buildNotifiers(config)
+	escalationNotifiers = buildEscalationNotifiers(config)
+
+	runTestCheck(t, testPageOutage)
+	backdateTestOutage(t, time.Now().Add(-2*time.Hour))
+	runTestCheck(t, testPageOutage)
+
+	if got := countEscalations(notifications.Payloads()); got != 1 {
+		t.Errorf("expected the escalation to go to the regular notifiers, got %d", got)
+	}
+}
+
+func TestEscalationRespectsMinFeedSeverity(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	escalationServer, escalations := serveWebhook(t, http.StatusOK)
+
+	config.EscalationAfter = time.Hour
+	config.EscalationWebhookURL = escalationServer.URL
+	config.MinFeedSeverity = SeverityMajor
+	config.MajorSeverityFraction = 0.9
+	escalationNotifiers = buildEscalationNotifiers(config)
+
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, testPageOutage)
+	backdateTestOutage(t, time.Now().Add(-2*time.Hour))
+	runTestCheck(t, testPageOutage)
+
+	if got := countEscalations(escalations.Payloads()); got != 0 {
+		t.Errorf("expected a minor outage below the minimum severity not to be escalated, got %d", got)
+	}
+}
+
+func TestEscalationIsRetriedWhenDeliveryFails(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	escalationServer, escalations := serveWebhook(t, http.StatusInternalServerError)
+
+	config.EscalationAfter = time.Hour
+	config.EscalationWebhookURL = escalationServer.URL
+	escalationNotifiers = buildEscalationNotifiers(config)
+
+	runTestCheck(t, testPageOutage)
+	backdateTestOutage(t, time.Now().Add(-2*time.Hour))
+	runTestCheck(t, testPageOutage)
+	runTestCheck(t, testPageOutage)
+
+	if got := countEscalations(escalations.Payloads()); got != 2 {
+		t.Errorf("expected the failed escalation to be retried, got %d attempts", got)
+	}
+
+	lastStatus, err := queryLastStatus()
+
+	if err != nil {
+		t.Fatalf("error querying last status: %v", err)
+	}
+
+	if lastStatus.EscalationSent {
+		t.Errorf("expected an undelivered escalation not to be marked as sent")
+	}
+}
+
+func TestAdminConfigHandlerMasksEscalationWebhookURL(t *testing.T) {
+	setTestConfig(t, &Config{EscalationWebhookURL: "https://hooks.example.com/escalation-secret"})
+
+	w := httptest.NewRecorder()
+	adminConfigHandler()(w, httptest.NewRequest(http.MethodGet, "/admin/config", nil))
+
+	if strings.Contains(w.Body.String(), "escalation-secret") {
+		t.Errorf("expected the escalation webhook URL to be masked in %s", w.Body.String())
+	}
+}
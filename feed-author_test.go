@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeedAuthor(t *testing.T) {
+	feed := []*Feed{{Title: "All services operational", PubDate: time.Now()}}
+
+	tests := []struct {
+		name   string
+		render func([]*Feed) ([]byte, error)
+		want   string
+	}{
+		{name: "rss", render: renderRssFeed, want: `<dc:creator>Ops Team</dc:creator>`},
+		{name: "atom", render: renderAtomFeed, want: `<author><name>Ops Team</name></author>`},
+		{name: "json", render: renderJsonFeed, want: `"authors":[{"name":"Ops Team"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, &Config{StatusPageURL: "https://status.example.com"})
+
+			b, err := tt.render(feed)
+
+			if err != nil {
+				t.Fatalf("error rendering feed: %v", err)
+			}
+
+			if strings.Contains(string(b), "Ops Team") {
+				t.Errorf("expected no author when unset, got %s", b)
+			}
+
+			config.FeedAuthor = "Ops Team"
+
+			if b, err = tt.render(feed); err != nil {
+				t.Fatalf("error rendering feed: %v", err)
+			}
+
+			if !strings.Contains(string(b), tt.want) {
+				t.Errorf("expected %s in %s", tt.want, b)
+			}
+		})
+	}
+}
+
+func TestRssFeedAuthorDeclaresDublinCore(t *testing.T) {
+	setTestConfig(t, &Config{FeedAuthor: "Ops Team"})
+
+	b, err := renderRssFeed([]*Feed{{Title: "All services operational", PubDate: time.Now()}})
+
+	if err != nil {
+		t.Fatalf("error rendering feed: %v", err)
+	}
+
+	if !strings.Contains(string(b), `xmlns:dc="http://purl.org/dc/elements/1.1/"`) {
+		t.Errorf("expected the Dublin Core namespace to be declared, got %s", b)
+	}
+}
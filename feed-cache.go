@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+FeedVersion identifies the contents of the feeds table. A cached rendering
+is only served while the version it was rendered from is still current,
+so an entry written after invalidateFeedCache by a slower replica can't
+hide a newer item. NewestUpdatedAt covers items moved to the top of the
+feed by refreshFeedItem, which changes neither the newest ID nor the
+count. It is read as text so SQLite and Postgres compare it the same way.
+
+With Config.DynamicChannelDescription on, StatusHash ties the rendering to
+the state in the channel description, which can change without an item
+being written, such as when a muted service goes down.
+*/
+type FeedVersion struct {
+	NewestFeedID    uint
+	FeedCount       int64
+	NewestUpdatedAt string
+	StatusHash      string `gorm:"-"`
+}
+
+func queryFeedVersion() (FeedVersion, error) {
+	var (
+		err        error
+		version    FeedVersion
+		lastStatus *LastStatus
+	)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	version, err = gorm.G[FeedVersion](db).Table("feeds").
+		Select("COALESCE(MAX(id), 0) AS newest_feed_id, COUNT(*) AS feed_count, COALESCE(CAST(MAX(updated_at) AS TEXT), '') AS newest_updated_at").
+		Where("deleted_at IS NULL").
+		Take(ctx)
+
+	if err != nil || !config.DynamicChannelDescription {
+		return version, err
+	}
+
+	if lastStatus, err = queryLastStatus(); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return version, err
+	}
+
+	if lastStatus != nil {
+		version.StatusHash = lastStatus.LastStatusHash
+	}
+
+	return version, nil
+}
+
+/*
+Matches reports whether the cache entry was rendered from version.
+*/
+func (fc *FeedCache) Matches(version FeedVersion) bool {
+	return fc.NewestFeedID == version.NewestFeedID && fc.FeedCount == version.FeedCount && fc.NewestUpdatedAt == version.NewestUpdatedAt &&
+		fc.StatusHash == version.StatusHash
+}
+
+func queryFeedCache(format string, limit int) (*FeedCache, error) {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	return gorm.G[*FeedCache](db).Where("format=? AND item_limit=?", format, limit).First(ctx)
+}
+
+/*
+upsertFeedCache stores a rendered feed along with the feed version it was
+rendered from. Replicas racing to fill the same entry may overwrite each
+other, but an outdated entry is ignored by renderFeed because its version
+no longer matches.
+*/
+func upsertFeedCache(format string, limit int, body []byte, etag string, newestAt time.Time, version FeedVersion) error {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	entry := FeedCache{
+		Format:          format,
+		ItemLimit:       limit,
+		ETag:            etag,
+		NewestAt:        newestAt,
+		NewestFeedID:    version.NewestFeedID,
+		FeedCount:       version.FeedCount,
+		NewestUpdatedAt: version.NewestUpdatedAt,
+		StatusHash:      version.StatusHash,
+		Body:            body,
+	}
+
+	return gorm.G[FeedCache](db, clause.OnConflict{
+		Columns:   []clause.Column{{Name: "format"}, {Name: "item_limit"}},
+		DoUpdates: clause.AssignmentColumns([]string{"etag", "newest_at", "newest_feed_id", "feed_count", "newest_updated_at", "status_hash", "body", "created_at"}),
+	}).Create(ctx, &entry)
+}
+
+/*
+invalidateFeedCache removes every cached rendering. It is called whenever
+a feed item is written.
+*/
+func invalidateFeedCache() error {
+	clearMemoryFeedCache()
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	_, err := gorm.G[FeedCache](db).Where("1 = 1").Delete(ctx)
+	return err
+}
+
+type memoryFeedCacheEntry struct {
+	body      []byte
+	etag      string
+	expiresAt time.Time
+}
+
+/*
+memoryFeedCache holds rendered feeds for Config.FeedCacheTTL so a burst
+of requests doesn't render the same feed over and over. It is cleared
+when this replica writes a feed item, or records a new status while the
+channel description is dynamic. Other replicas pick up the change once
+their entries expire.
+*/
+var memoryFeedCache = struct {
+	sync.Mutex
+	entries map[string]memoryFeedCacheEntry
+}{entries: map[string]memoryFeedCacheEntry{}}
+
+func memoryFeedCacheKey(format string, limit int) string {
+	return fmt.Sprintf("%s:%d", format, limit)
+}
+
+func getMemoryFeedCache(format string, limit int) ([]byte, string, bool) {
+	memoryFeedCache.Lock()
+	defer memoryFeedCache.Unlock()
+
+	entry, ok := memoryFeedCache.entries[memoryFeedCacheKey(format, limit)]
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, "", false
+	}
+
+	return entry.body, entry.etag, true
+}
+
+func putMemoryFeedCache(format string, limit int, body []byte, etag string, ttl time.Duration) {
+	memoryFeedCache.Lock()
+	defer memoryFeedCache.Unlock()
+
+	memoryFeedCache.entries[memoryFeedCacheKey(format, limit)] = memoryFeedCacheEntry{
+		body:      body,
+		etag:      etag,
+		expiresAt: time.Now().Add(ttl),
+	}
+}
+
+func clearMemoryFeedCache() {
+	memoryFeedCache.Lock()
+	defer memoryFeedCache.Unlock()
+
+	clear(memoryFeedCache.entries)
+}
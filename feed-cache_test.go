@@ -0,0 +1,206 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestRenderFeedIgnoresCacheFromOlderVersion(t *testing.T) {
+	setupTestDB(t)
+	config.CacheFeedInDB = true
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if err := gorm.G[Feed](db).Create(ctx, &Feed{Title: "first", PubDate: time.Now()}); err != nil {
+		t.Fatalf("error creating feed item: %v", err)
+	}
+
+	stale, _, err := renderFeed(feedFormatRss, 10)
+
+	if err != nil {
+		t.Fatalf("error rendering feed: %v", err)
+	}
+
+	/*
+	 * Simulates a slow replica storing its rendering after another replica
+	 * wrote a newer item and invalidated the cache.
+	 */
+	if err = gorm.G[Feed](db).Create(ctx, &Feed{Title: "second", PubDate: time.Now()}); err != nil {
+		t.Fatalf("error creating feed item: %v", err)
+	}
+
+	if err = invalidateFeedCache(); err != nil {
+		t.Fatalf("error invalidating feed cache: %v", err)
+	}
+
+	if err = upsertFeedCache(feedFormatRss, 10, stale, `"stale"`, time.Now(), FeedVersion{NewestFeedID: 1, FeedCount: 1}); err != nil {
+		t.Fatalf("error storing feed cache: %v", err)
+	}
+
+	b, etag, err := renderFeed(feedFormatRss, 10)
+
+	if err != nil {
+		t.Fatalf("error rendering feed: %v", err)
+	}
+
+	if etag == `"stale"` {
+		t.Fatalf("expected the outdated cache entry to be ignored")
+	}
+
+	if string(b) == string(stale) {
+		t.Fatalf("expected the rendered feed to include the newer item")
+	}
+
+	cached, err := queryFeedCache(feedFormatRss, 10)
+
+	if err != nil {
+		t.Fatalf("error querying feed cache: %v", err)
+	}
+
+	if cached.NewestFeedID != 2 || cached.FeedCount != 2 {
+		t.Errorf("expected the cache to be refreshed to the current version, got %d/%d", cached.NewestFeedID, cached.FeedCount)
+	}
+}
+
+func TestRenderFeedIgnoresCacheFromBeforeRefresh(t *testing.T) {
+	setupTestDB(t)
+	config.CacheFeedInDB = true
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if err := gorm.G[Feed](db).Create(ctx, &Feed{Title: "first", PubDate: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatalf("error creating feed item: %v", err)
+	}
+
+	version, err := queryFeedVersion()
+
+	if err != nil {
+		t.Fatalf("error querying feed version: %v", err)
+	}
+
+	/*
+	 * A deduplicated item is moved to the top with a fresh timestamp. A
+	 * rendering stored afterwards under the earlier version must not be
+	 * served.
+	 */
+	time.Sleep(10 * time.Millisecond)
+
+	if err = refreshFeedItem(1, time.Now()); err != nil {
+		t.Fatalf("error refreshing feed item: %v", err)
+	}
+
+	if err = upsertFeedCache(feedFormatRss, 10, []byte("stale"), `"stale"`, time.Now(), version); err != nil {
+		t.Fatalf("error storing feed cache: %v", err)
+	}
+
+	if _, etag, err := renderFeed(feedFormatRss, 10); err != nil || etag == `"stale"` {
+		t.Errorf("expected the entry from before the refresh to be ignored, got %s (%v)", etag, err)
+	}
+}
+
+func TestRenderFeedServesCurrentCache(t *testing.T) {
+	setupTestDB(t)
+	config.CacheFeedInDB = true
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if err := gorm.G[Feed](db).Create(ctx, &Feed{Title: "first", PubDate: time.Now()}); err != nil {
+		t.Fatalf("error creating feed item: %v", err)
+	}
+
+	version, err := queryFeedVersion()
+
+	if err != nil {
+		t.Fatalf("error querying feed version: %v", err)
+	}
+
+	if err = upsertFeedCache(feedFormatRss, 10, []byte("cached"), `"cached"`, time.Now(), version); err != nil {
+		t.Fatalf("error storing feed cache: %v", err)
+	}
+
+	b, etag, err := renderFeed(feedFormatRss, 10)
+
+	if err != nil {
+		t.Fatalf("error rendering feed: %v", err)
+	}
+
+	if string(b) != "cached" || etag != `"cached"` {
+		t.Errorf("expected the cached rendering, got %q %s", b, etag)
+	}
+}
+
+func TestRenderFeedMemoryCache(t *testing.T) {
+	setupTestDB(t)
+	config.FeedCacheTTL = time.Hour
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if err := gorm.G[Feed](db).Create(ctx, &Feed{Title: "first", PubDate: time.Now()}); err != nil {
+		t.Fatalf("error creating feed item: %v", err)
+	}
+
+	first, _, err := renderFeed(feedFormatRss, 10)
+
+	if err != nil {
+		t.Fatalf("error rendering feed: %v", err)
+	}
+
+	/*
+	 * An item written by another replica is picked up once the entry
+	 * expires, not straight away.
+	 */
+	if err = gorm.G[Feed](db).Create(ctx, &Feed{Title: "second", PubDate: time.Now()}); err != nil {
+		t.Fatalf("error creating feed item: %v", err)
+	}
+
+	if b, _, _ := renderFeed(feedFormatRss, 10); string(b) != string(first) {
+		t.Errorf("expected the in-memory rendering to be served within the TTL")
+	}
+
+	if b, _, _ := renderFeed(feedFormatRss, 20); string(b) == string(first) {
+		t.Errorf("expected each limit to be cached separately")
+	}
+
+	/*
+	 * An item written by this replica clears the cache.
+	 */
+	if _, err = insertRssItem(RssItem{Title: "third", PubDate: time.Now()}); err != nil {
+		t.Fatalf("error inserting feed item: %v", err)
+	}
+
+	b, _, err := renderFeed(feedFormatRss, 10)
+
+	if err != nil {
+		t.Fatalf("error rendering feed: %v", err)
+	}
+
+	if !strings.Contains(string(b), "third") || !strings.Contains(string(b), "second") {
+		t.Errorf("expected a fresh rendering after an insert, got %s", b)
+	}
+}
+
+func TestMemoryFeedCacheExpires(t *testing.T) {
+	t.Cleanup(clearMemoryFeedCache)
+
+	putMemoryFeedCache(feedFormatRss, 10, []byte("feed"), `"etag"`, time.Hour)
+	putMemoryFeedCache(feedFormatJson, 10, []byte("feed"), `"etag"`, -time.Second)
+
+	if b, etag, ok := getMemoryFeedCache(feedFormatRss, 10); !ok || string(b) != "feed" || etag != `"etag"` {
+		t.Errorf("expected a live entry, got %q %q %v", b, etag, ok)
+	}
+
+	if _, _, ok := getMemoryFeedCache(feedFormatJson, 10); ok {
+		t.Errorf("expected an expired entry to be ignored")
+	}
+
+	if _, _, ok := getMemoryFeedCache(feedFormatRss, 20); ok {
+		t.Errorf("expected no entry for another limit")
+	}
+}
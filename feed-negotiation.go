@@ -0,0 +1,107 @@
+package main
+
+import (
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/adampresley/httphelpers/responses"
+)
+
+/*
+feedMediaTypes maps the media types accepted by /feed to a feed format.
+*/
+var feedMediaTypes = map[string]string{
+	"application/rss+xml":   feedFormatRss,
+	"application/atom+xml":  feedFormatAtom,
+	"application/feed+json": feedFormatJson,
+	"application/json":      feedFormatJson,
+}
+
+var feedContentTypes = map[string]string{
+	feedFormatRss:  "application/rss+xml",
+	feedFormatAtom: "application/atom+xml",
+	feedFormatJson: "application/feed+json",
+}
+
+/*
+feedHandler serves the feed in whichever format the Accept header prefers,
+defaulting to RSS. With Config.ValidateFeedOutput the XML formats are
+checked the same way /status.rss is.
+*/
+func feedHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err  error
+			b    []byte
+			etag string
+		)
+
+		if err = postToAnalytics(r); err != nil {
+			slog.Error("error posting to analytics", "error", err)
+		}
+
+		format := negotiateFeedFormat(r.Header.Get("Accept"))
+		w.Header().Set("Vary", "Accept")
+
+		if b, etag, err = renderFeed(format, parseLimit(r, 10, 100)); err != nil {
+			slog.Error("error rendering feed", "format", format, "error", err)
+			responses.TextInternalServerError(w, "An unexpected error occurred while rendering the feed")
+			return
+		}
+
+		if config.ValidateFeedOutput && format != feedFormatJson {
+			if err = validateFeedXml(b); err != nil {
+				slog.Error("rendered feed failed validation", "format", format, "error", err)
+				responses.TextInternalServerError(w, "An unexpected error occurred while rendering the feed")
+				return
+			}
+		}
+
+		writeFeed(w, r, feedContentTypes[format], b, etag)
+	}
+}
+
+/*
+negotiateFeedFormat picks the supported feed format with the highest
+quality value in an Accept header. Ties go to the type listed first.
+*/
+func negotiateFeedFormat(accept string) string {
+	var (
+		err       error
+		mediaType string
+		params    map[string]string
+	)
+
+	result := feedFormatRss
+	bestQuality := 0.0
+
+	for mediaRange := range strings.SplitSeq(accept, ",") {
+		if mediaType, params, err = mime.ParseMediaType(strings.TrimSpace(mediaRange)); err != nil {
+			continue
+		}
+
+		format, ok := feedMediaTypes[mediaType]
+
+		if !ok {
+			continue
+		}
+
+		quality := 1.0
+
+		if q, hasQuality := params["q"]; hasQuality {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		if quality > bestQuality {
+			result = format
+			bestQuality = quality
+		}
+	}
+
+	return result
+}
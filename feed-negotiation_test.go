@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adampresley/rester/clientoptions"
+)
+
+func TestNegotiateFeedFormat(t *testing.T) {
+	tests := map[string]string{
+		"":                     feedFormatRss,
+		"text/html":            feedFormatRss,
+		"application/atom+xml": feedFormatAtom,
+		"application/json":     feedFormatJson,
+		"application/feed+json;q=0.5, application/atom+xml;q=0.9": feedFormatAtom,
+		"application/atom+xml, application/rss+xml":               feedFormatAtom,
+		"application/rss+xml;q=0.1, application/feed+json":        feedFormatJson,
+		"application/atom+xml;q=bad, application/rss+xml;q=0.2":   feedFormatRss,
+	}
+
+	for accept, want := range tests {
+		if got := negotiateFeedFormat(accept); got != want {
+			t.Errorf("Accept %q: expected %s, got %s", accept, want, got)
+		}
+	}
+}
+
+func TestFeedHandlerTracksRequestedPath(t *testing.T) {
+	var (
+		payload AleticsPayload
+	)
+
+	setupTestDB(t)
+
+	analytics := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.Write([]byte(`"ok"`))
+	}))
+	defer analytics.Close()
+
+	previousUseAletics, previousOptions := useAletics, aleticsClientOptions
+
+	t.Cleanup(func() {
+		useAletics, aleticsClientOptions = previousUseAletics, previousOptions
+	})
+
+	useAletics = true
+	aleticsClientOptions = clientoptions.New(analytics.URL)
+
+	r := httptest.NewRequest(http.MethodGet, "/feed", nil)
+	r.Header.Set("Accept", "application/atom+xml")
+
+	w := httptest.NewRecorder()
+	feedHandler()(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	if got := w.Header().Get("Content-Type"); got != feedContentTypes[feedFormatAtom] {
+		t.Errorf("expected content type %s, got %s", feedContentTypes[feedFormatAtom], got)
+	}
+
+	if payload.Path != "/feed" {
+		t.Errorf("expected analytics for /feed, got %q", payload.Path)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+/*
+validateFeedXml confirms a rendered feed is well-formed XML by reading
+every token. It is a safety net for Config.ValidateFeedOutput, so a
+malformed description fails the request instead of serving a broken feed.
+*/
+func validateFeedXml(b []byte) error {
+	var (
+		err error
+	)
+
+	decoder := xml.NewDecoder(bytes.NewReader(b))
+
+	for {
+		if _, err = decoder.Token(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return fmt.Errorf("feed is not valid XML: %w", err)
+		}
+	}
+}
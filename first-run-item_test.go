@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFirstRunItem(t *testing.T) {
+	tests := []struct {
+		name         string
+		firstRunItem string
+		wantTitle    string
+	}{
+		{name: "current-state", firstRunItem: FirstRunItemCurrentState, wantTitle: "1 services reporting potential issues"},
+		{name: "baseline", firstRunItem: FirstRunItemBaseline, wantTitle: "Monitoring started"},
+		{name: "none", firstRunItem: FirstRunItemNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			seedTestStatuses(t)
+
+			config.FirstRunItem = tt.firstRunItem
+
+			result := runTestCheck(t, testPageOutage)
+			feed := queryTestFeed(t)
+
+			if tt.wantTitle == "" {
+				if len(feed) != 0 || result.ItemWritten {
+					t.Errorf("expected no first-run item, got %d items", len(feed))
+				}
+			} else if len(feed) != 1 || feed[0].Title != tt.wantTitle {
+				t.Errorf("expected a %q item, got %+v", tt.wantTitle, feed)
+			}
+
+			if _, err := queryLastStatus(); err != nil {
+				t.Errorf("expected the initial status to be recorded, got %v", err)
+			}
+
+			/*
+			 * Later changes are reported against the recorded state whatever
+			 * the first run wrote.
+			 */
+			runTestCheck(t, testPageOperational)
+
+			if latest := queryTestFeed(t); len(latest) != len(feed)+1 {
+				t.Errorf("expected the recovery to add one item, got %d", len(latest)-len(feed))
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type HealthResponse struct {
+	Status          string     `json:"status"`
+	Database        string     `json:"database"`
+	LastSuccessAt   *time.Time `json:"lastSuccessAt,omitempty"`
+	PageUpdatedAt   *time.Time `json:"pageUpdatedAt,omitempty"`
+	LockContentions int64      `json:"lockContentions"`
+	SlowChecks      int64      `json:"slowChecks"`
+	Message         string     `json:"message,omitempty"`
+}
+
+/*
+healthzHandler reports readiness. The server is unavailable when the
+database cannot be reached, or when Config.MaxStaleness is set and the
+last successful status check is older than it.
+*/
+func healthzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err        error
+			sqlDB      *sql.DB
+			lastStatus *LastStatus
+		)
+
+		result := HealthResponse{
+			Status:          "ok",
+			Database:        "ok",
+			LockContentions: cronLockContentions.Load(),
+			SlowChecks:      latencyAnomalies.Load(),
+		}
+
+		ctx, cancel := getContext()
+		defer cancel()
+
+		if sqlDB, err = db.DB(); err == nil {
+			err = sqlDB.PingContext(ctx)
+		}
+
+		if err != nil {
+			slog.Error("health check database ping failed", "error", err)
+
+			result.Status = "unavailable"
+			result.Database = "unreachable"
+			writeJson(w, r, http.StatusServiceUnavailable, result)
+			return
+		}
+
+		if lastStatus, err = queryLastStatus(); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Error("health check error querying last status", "error", err)
+
+			result.Status = "unavailable"
+			result.Message = "unable to determine the last successful check"
+			writeJson(w, r, http.StatusServiceUnavailable, result)
+			return
+		}
+
+		if lastStatus != nil && !lastStatus.LastSuccessAt.IsZero() {
+			result.LastSuccessAt = &lastStatus.LastSuccessAt
+		}
+
+		if lastStatus != nil && !lastStatus.PageUpdatedAt.IsZero() {
+			result.PageUpdatedAt = &lastStatus.PageUpdatedAt
+		}
+
+		if config.MaxStaleness > 0 {
+			if result.LastSuccessAt == nil || time.Since(*result.LastSuccessAt) > config.MaxStaleness {
+				result.Status = "unavailable"
+				result.Message = "the last successful status check is older than " + config.MaxStaleness.String()
+				writeJson(w, r, http.StatusServiceUnavailable, result)
+				return
+			}
+		}
+
+		writeJsonOK(w, r, result)
+	}
+}
+
+/*
+recordCheckSuccess stamps the time of the latest successful status check,
+which /healthz compares against Config.MaxStaleness.
+*/
+func recordCheckSuccess() {
+	var (
+		err error
+	)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if _, err = gorm.G[LastStatus](db).Where("id=1").Update(ctx, "last_success_at", time.Now()); err != nil {
+		slog.Error("error recording successful check", "error", err)
+	}
+}
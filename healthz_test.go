@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func getTestHealth(t *testing.T) (HealthResponse, int) {
+	t.Helper()
+
+	var (
+		health HealthResponse
+	)
+
+	w := httptest.NewRecorder()
+	healthzHandler()(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if err := json.Unmarshal(w.Body.Bytes(), &health); err != nil {
+		t.Fatalf("error decoding health response: %v", err)
+	}
+
+	return health, w.Code
+}
+
+func TestHealthzStaleness(t *testing.T) {
+	setupTestDB(t)
+	config.MaxStaleness = time.Hour
+
+	if health, code := getTestHealth(t); code != http.StatusServiceUnavailable || health.Status != "unavailable" {
+		t.Errorf("expected unavailable before the first check, got %d %+v", code, health)
+	}
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if err := gorm.G[LastStatus](db).Create(ctx, &LastStatus{LastSuccessAt: time.Now().Add(-2 * time.Hour)}); err != nil {
+		t.Fatalf("error creating last status: %v", err)
+	}
+
+	if _, code := getTestHealth(t); code != http.StatusServiceUnavailable {
+		t.Errorf("expected unavailable with a stale last success, got %d", code)
+	}
+
+	recordCheckSuccess()
+
+	health, code := getTestHealth(t)
+
+	if code != http.StatusOK || health.Status != "ok" || health.LastSuccessAt == nil {
+		t.Errorf("expected ok after a successful check, got %d %+v", code, health)
+	}
+}
+
+func TestHealthzWithoutStalenessGate(t *testing.T) {
+	setupTestDB(t)
+
+	if health, code := getTestHealth(t); code != http.StatusOK || health.Database != "ok" {
+		t.Errorf("expected ok, got %d %+v", code, health)
+	}
+}
+
+func TestHealthzDatabaseUnreachable(t *testing.T) {
+	setupTestDB(t)
+
+	sqlDB, err := db.DB()
+
+	if err != nil {
+		t.Fatalf("error getting database handle: %v", err)
+	}
+
+	sqlDB.Close()
+
+	if health, code := getTestHealth(t); code != http.StatusServiceUnavailable || health.Database != "unreachable" {
+		t.Errorf("expected an unreachable database, got %d %+v", code, health)
+	}
+}
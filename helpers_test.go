@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+/*
+setupTestDB points the package globals at a fresh, migrated SQLite
+database and a default config. Tests may adjust config afterwards.
+Everything is restored when the test finishes.
+*/
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	connectTestDB(t)
+
+	if err := runMigrations(); err != nil {
+		t.Fatalf("error migrating test database: %v", err)
+	}
+}
+
+/*
+connectTestDB is setupTestDB without the migrations, for tests that build
+an older schema themselves.
+*/
+func connectTestDB(t *testing.T) {
+	t.Helper()
+
+	previousConfig, previousDB, previousReadDB := config, db, readDB
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+
+		config, db, readDB = previousConfig, previousDB, previousReadDB
+		resetTestState()
+	})
+
+	config = &Config{
+		DSN:                   "file:" + filepath.Join(t.TempDir(), "test.db"),
+		FirstRunItem:          "current-state",
+		HashMode:              "full",
+		ItemGranularity:       "combined",
+		MajorSeverityFraction: 0.5,
+		SLOTarget:             0.999,
+	}
+
+	if err := connectDatabase(); err != nil {
+		t.Fatalf("error connecting to test database: %v", err)
+	}
+}
+
+/*
+setTestConfig replaces the global config for the duration of a test.
+*/
+func setTestConfig(t *testing.T, c *Config) {
+	t.Helper()
+
+	previousConfig := config
+	t.Cleanup(func() { config = previousConfig })
+
+	config = c
+}
+
+/*
+testServicePage renders a minimal status page in the markup the default
+selectors expect. Each entry is a service name and its icon class.
+*/
+func testServicePage(entries ...[2]string) string {
+	page := strings.Builder{}
+	page.WriteString(`<html><body>`)
+
+	for _, entry := range entries {
+		fmt.Fprintf(&page, `<div class="flex-col"><p>%s</p><i class="%s"></i></div>`, entry[0], entry[1])
+	}
+
+	page.WriteString(`</body></html>`)
+	return page.String()
+}
+
+/*
+serveStatusPage starts a server that responds with body and points
+Config.StatusPageURL at it.
+*/
+func serveStatusPage(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+
+	t.Cleanup(server.Close)
+
+	config.StatusPageURL = server.URL
+	return server
+}
+
+/*
+seedTestStatuses stores an operational and an outage status and two
+services, returning them the way main queries them at startup.
+*/
+func seedTestStatuses(t *testing.T) ([]*Service, []*Status) {
+	t.Helper()
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	statuses := []*Status{
+		{Status: "Operational", ClassName: "ok"},
+		{Status: "Outage", ClassName: "down", IsError: true},
+	}
+
+	services := []*Service{
+		{ServiceName: "Checkout", Active: true},
+		{ServiceName: "Storefront", Active: true},
+	}
+
+	for _, status := range statuses {
+		if err := gorm.G[Status](db).Create(ctx, status); err != nil {
+			t.Fatalf("error creating status: %v", err)
+		}
+	}
+
+	for _, service := range services {
+		if err := gorm.G[Service](db).Create(ctx, service); err != nil {
+			t.Fatalf("error creating service: %v", err)
+		}
+	}
+
+	return services, statuses
+}
+
+/*
+resetTestState clears the state package globals keep between checks.
+*/
+func resetTestState() {
+	clearMemoryFeedCache()
+
+	unsaved.Lock()
+	unsaved.lastStatus, unsaved.statusPending, unsaved.items = nil, false, nil
+	unsaved.Unlock()
+
+	reportedStatusClasses.Lock()
+	reportedStatusClasses.classes = nil
+	reportedStatusClasses.Unlock()
+
+	latencyEma.Lock()
+	latencyEma.average, latencyEma.samples = 0, 0
+	latencyEma.Unlock()
+
+	notifiers, escalationNotifiers = nil, nil
+}
+
+/*
+runTestCheck serves page as the status page and runs one check against
+the active services and statuses in the database.
+*/
+func runTestCheck(t *testing.T, page string) CronResult {
+	t.Helper()
+
+	serveStatusPage(t, page)
+
+	services, err := queryServices()
+
+	if err != nil {
+		t.Fatalf("error querying services: %v", err)
+	}
+
+	statuses, err := queryStatuses()
+
+	if err != nil {
+		t.Fatalf("error querying statuses: %v", err)
+	}
+
+	return cronJob(services, statuses)
+}
+
+/*
+queryTestFeed returns every feed item, newest first.
+*/
+func queryTestFeed(t *testing.T) []*Feed {
+	t.Helper()
+
+	feed, err := queryFeedFrom(db, 100)
+
+	if err != nil {
+		t.Fatalf("error querying feed: %v", err)
+	}
+
+	return feed
+}
+
+var (
+	testPageOperational = testServicePage([2]string{"Checkout", "ok"}, [2]string{"Storefront", "ok"})
+	testPageOutage      = testServicePage([2]string{"Checkout", "ok"}, [2]string{"Storefront", "down"})
+)
+
+/*
+getTestRss requests target from statusRssHandler and decodes the feed.
+*/
+func getTestRss(t *testing.T, target string) (RssFeed, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	var (
+		feed RssFeed
+	)
+
+	w := httptest.NewRecorder()
+	statusRssHandler()(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 from %s, got %d: %s", target, w.Code, w.Body.String())
+	}
+
+	if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("error decoding RSS feed: %v", err)
+	}
+
+	return feed, w
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseIncidentLink(t *testing.T) {
+	setTestConfig(t, &Config{IncidentLinkSelector: "a[href*='/incidents/']"})
+
+	tests := map[string]string{
+		`<a href="/about">About</a>`:                                        "",
+		`<a href="/incidents/abc123">Checkout issues</a>`:                   "https://www.shopifystatus.com/incidents/abc123",
+		`<a href=" https://status.example.com/incidents/9 ">Elsewhere</a>`:  "https://status.example.com/incidents/9",
+		`<a href="/incidents/1">First</a><a href="/incidents/2">Second</a>`: "https://www.shopifystatus.com/incidents/1",
+	}
+
+	for html, want := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+		if err != nil {
+			t.Fatalf("error parsing document: %v", err)
+		}
+
+		if got := parseIncidentLink(doc, "https://www.shopifystatus.com/"); got != want {
+			t.Errorf("%s: expected %q, got %q", html, want, got)
+		}
+	}
+}
+
+func TestCronJobLinksErrorItemsToIncidents(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.PreferIncidentLinks = true
+	config.IncidentLinkSelector = "a[href*='/incidents/']"
+
+	runTestCheck(t, strings.Replace(testPageOutage, "<body>", `<body><a href="/incidents/42">Storefront outage</a>`, 1))
+
+	feed := queryTestFeed(t)
+
+	if len(feed) != 1 || feed[0].Link != config.StatusPageURL+"/incidents/42" {
+		t.Errorf("expected the error item to link to the incident, got %+v", feed)
+	}
+}
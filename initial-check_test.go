@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestRunInitialCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		async bool
+	}{
+		{name: "sync", async: false},
+		{name: "async", async: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			services, statuses := seedTestStatuses(t)
+
+			config.AsyncInitialCheck = tt.async
+			release := make(chan struct{})
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-release
+				fmt.Fprint(w, testPageOperational)
+			}))
+
+			t.Cleanup(server.Close)
+			config.StatusPageURL = server.URL
+
+			if !tt.async {
+				close(release)
+			}
+
+			done := runInitialCheck(services, statuses)
+
+			if tt.async {
+				select {
+				case <-done:
+					t.Fatalf("expected the async check to still be waiting on the status page")
+				default:
+				}
+
+				if _, err := queryLastStatus(); !errors.Is(err, gorm.ErrRecordNotFound) {
+					t.Fatalf("expected no last status before the page responds, got %v", err)
+				}
+
+				close(release)
+			}
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("initial check did not finish")
+			}
+
+			if _, err := queryLastStatus(); err != nil {
+				t.Errorf("expected the initial check to record a last status, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRunInitialCheckTakesCheckLock(t *testing.T) {
+	setupTestDB(t)
+	services, statuses := seedTestStatuses(t)
+	serveStatusPage(t, testPageOperational)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	locker := &PostgresLocker{DB: db}
+
+	if err := locker.Lock(ctx, checkStatusLockKey); err != nil {
+		t.Fatalf("error taking the check lock: %v", err)
+	}
+
+	<-runInitialCheck(services, statuses)
+
+	if _, err := queryLastStatus(); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected the initial check to be skipped while a check holds the lock, got %v", err)
+	}
+
+	if err := locker.Unlock(ctx, checkStatusLockKey); err != nil {
+		t.Fatalf("error releasing the check lock: %v", err)
+	}
+
+	<-runInitialCheck(services, statuses)
+
+	if _, err := queryLastStatus(); err != nil {
+		t.Errorf("expected the initial check to run once the lock is free, got %v", err)
+	}
+
+	if count, err := gorm.G[CronLock](db).Count(ctx, "*"); err != nil || count != 0 {
+		t.Errorf("expected the initial check to release the lock, got %d (%v)", count, err)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/adampresley/httphelpers/responses"
+)
+
+/*
+JsonFeed is a JSON Feed 1.1 document. See https://www.jsonfeed.org/version/1.1/
+*/
+type JsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	Description string           `json:"description"`
+	Authors     []JsonFeedAuthor `json:"authors,omitempty"`
+	Items       []JsonFeedItem   `json:"items"`
+}
+
+type JsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type JsonFeedItem struct {
+	ID            string    `json:"id"`
+	URL           string    `json:"url"`
+	Title         string    `json:"title"`
+	ContentHTML   string    `json:"content_html"`
+	DatePublished time.Time `json:"date_published"`
+	Severity      string    `json:"severity"`
+}
+
+func statusJsonHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err  error
+			b    []byte
+			etag string
+		)
+
+		if err = postToAnalytics(r); err != nil {
+			slog.Error("error posting to analytics", "error", err)
+		}
+
+		if b, etag, err = renderFeed(feedFormatJson, parseLimit(r, 10, 100)); err != nil {
+			slog.Error("error rendering JSON feed", "error", err)
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, "An unexpected error occurred while rendering the JSON feed")
+			return
+		}
+
+		if wantsPrettyJson(r) {
+			b = indentJsonBody(r, b)
+			etag = fmt.Sprintf(`"%x"`, sha256.Sum256(b))
+		}
+
+		writeFeed(w, r, "application/feed+json", b, etag)
+	}
+}
+
+func renderJsonFeed(feed []*Feed) ([]byte, error) {
+	result := JsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feedTitle,
+		HomePageURL: config.StatusPageURL,
+		Description: feedDescription,
+		Items:       []JsonFeedItem{},
+	}
+
+	if config.FeedAuthor != "" {
+		result.Authors = []JsonFeedAuthor{{Name: config.FeedAuthor}}
+	}
+
+	for _, f := range feed {
+		result.Items = append(result.Items, JsonFeedItem{
+			ID:            fmt.Sprintf("%d", f.ID),
+			URL:           f.GetLink(),
+			Title:         f.Title,
+			ContentHTML:   f.Description,
+			DatePublished: f.PubDate,
+			Severity:      f.GetSeverity(),
+		})
+	}
+
+	return json.Marshal(result)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusJsonHandler(t *testing.T) {
+	var (
+		feed JsonFeed
+	)
+
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, testPageOutage)
+
+	w := httptest.NewRecorder()
+	statusJsonHandler()(w, httptest.NewRequest(http.MethodGet, "/status.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("error decoding JSON feed: %v", err)
+	}
+
+	if feed.Version != "https://jsonfeed.org/version/1.1" || len(feed.Items) != 2 {
+		t.Fatalf("unexpected feed %+v", feed)
+	}
+
+	if feed.Items[0].Severity != SeverityMajor || feed.Items[1].Severity != SeverityInfo {
+		t.Errorf("expected major then info severities, got %s and %s", feed.Items[0].Severity, feed.Items[1].Severity)
+	}
+
+	if feed.Items[0].ID == "" || feed.Items[0].ID == feed.Items[1].ID {
+		t.Errorf("expected distinct item IDs, got %q and %q", feed.Items[0].ID, feed.Items[1].ID)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"embed"
+	"net/http"
+
+	"github.com/adampresley/httphelpers/responses"
+)
+
+//go:embed schema/*.json
+var schemaFS embed.FS
+
+/*
+schemaHandler serves an embedded JSON Schema describing one of the JSON
+response payloads.
+*/
+func schemaHandler(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err error
+			b   []byte
+		)
+
+		if b, err = schemaFS.ReadFile("schema/" + name); err != nil {
+			responses.JsonErrorMessage(w, http.StatusNotFound, "Schema not found")
+			return
+		}
+
+		responses.Bytes(w, http.StatusOK, "application/schema+json", b)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSchemaHandler(t *testing.T) {
+	for _, name := range []string{"current.json", "feed.json"} {
+		t.Run(name, func(t *testing.T) {
+			var (
+				schema map[string]any
+			)
+
+			w := httptest.NewRecorder()
+			schemaHandler(name)(w, httptest.NewRequest(http.MethodGet, "/schema/"+name, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d", w.Code)
+			}
+
+			if got := w.Header().Get("Content-Type"); got != "application/schema+json" {
+				t.Errorf("expected a schema content type, got %q", got)
+			}
+
+			if err := json.Unmarshal(w.Body.Bytes(), &schema); err != nil {
+				t.Fatalf("error decoding schema: %v", err)
+			}
+
+			if schema["$id"] != "/schema/"+name {
+				t.Errorf("expected $id /schema/%s, got %v", name, schema["$id"])
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	schemaHandler("missing.json")(w, httptest.NewRequest(http.MethodGet, "/schema/missing.json", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown schema, got %d", w.Code)
+	}
+}
+
+/*
+testJsonSchema is the part of a JSON Schema needed to compare it with a
+payload's keys.
+*/
+type testJsonSchema struct {
+	Required   []string                  `json:"required"`
+	Properties map[string]testJsonSchema `json:"properties"`
+	Items      *testJsonSchema           `json:"items"`
+}
+
+func readTestSchema(t *testing.T, name string) testJsonSchema {
+	t.Helper()
+
+	var (
+		schema testJsonSchema
+	)
+
+	b, err := schemaFS.ReadFile("schema/" + name)
+
+	if err != nil {
+		t.Fatalf("error reading schema: %v", err)
+	}
+
+	if err = json.Unmarshal(b, &schema); err != nil {
+		t.Fatalf("error decoding schema: %v", err)
+	}
+
+	return schema
+}
+
+/*
+assertPayloadMatchesSchema guards against a schema drifting from the
+payload: every required property must be present, and every key in the
+payload must be declared. Arrays of objects are checked item by item.
+*/
+func assertPayloadMatchesSchema(t *testing.T, path string, schema testJsonSchema, payload map[string]any) {
+	t.Helper()
+
+	for _, property := range schema.Required {
+		if _, ok := payload[property]; !ok {
+			t.Errorf("required property %s.%s missing from the payload", path, property)
+		}
+	}
+
+	for key, value := range payload {
+		property, ok := schema.Properties[key]
+
+		if !ok {
+			t.Errorf("payload property %s.%s is not in the schema", path, key)
+			continue
+		}
+
+		elements, isArray := value.([]any)
+
+		if !isArray || property.Items == nil {
+			continue
+		}
+
+		for _, element := range elements {
+			if object, isObject := element.(map[string]any); isObject {
+				assertPayloadMatchesSchema(t, path+"."+key+"[]", *property.Items, object)
+			}
+		}
+	}
+}
+
+func TestCurrentSchemaMatchesResponse(t *testing.T) {
+	var (
+		current map[string]any
+	)
+
+	setupTestDB(t)
+	seedTestStatuses(t)
+	runTestCheck(t, testPageOutage)
+
+	w := httptest.NewRecorder()
+	currentJsonHandler()(w, httptest.NewRequest(http.MethodGet, "/current.json", nil))
+
+	if err := json.Unmarshal(w.Body.Bytes(), &current); err != nil {
+		t.Fatalf("error decoding current status: %v", err)
+	}
+
+	assertPayloadMatchesSchema(t, "current", readTestSchema(t, "current.json"), current)
+}
+
+func TestFeedSchemaMatchesResponse(t *testing.T) {
+	var (
+		feed map[string]any
+	)
+
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.FeedAuthor = "Status Bot"
+	runTestCheck(t, testPageOutage)
+
+	w := httptest.NewRecorder()
+	statusJsonHandler()(w, httptest.NewRequest(http.MethodGet, "/status.json", nil))
+
+	if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("error decoding JSON feed: %v", err)
+	}
+
+	if items, _ := feed["items"].([]any); len(items) == 0 {
+		t.Fatalf("expected the feed to have items to compare, got %s", w.Body.String())
+	}
+
+	assertPayloadMatchesSchema(t, "feed", readTestSchema(t, "feed.json"), feed)
+}
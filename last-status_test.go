@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestLastStatusStaysSingleton(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, testPageOutage)
+	runTestCheck(t, testPageOperational)
+
+	/*
+	 * A second first-run insert, as two racing instances would do, updates
+	 * the singleton rather than adding a row.
+	 */
+	if err := insertLastStatus("racing", PageValidators{}, nil); err != nil {
+		t.Fatalf("error inserting last status: %v", err)
+	}
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	count, err := gorm.G[LastStatus](db).Count(ctx, "*")
+
+	if err != nil {
+		t.Fatalf("error counting last status rows: %v", err)
+	}
+
+	if count != 1 {
+		t.Errorf("expected exactly one last status row, got %d", count)
+	}
+}
+
+func TestDeleteStrayLastStatuses(t *testing.T) {
+	setupTestDB(t)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	for _, id := range []uint{1, 2, 3} {
+		if err := gorm.G[LastStatus](db).Create(ctx, &LastStatus{ID: id, LastStatusHash: "hash"}); err != nil {
+			t.Fatalf("error creating last status %d: %v", id, err)
+		}
+	}
+
+	removed, err := deleteStrayLastStatuses()
+
+	if err != nil {
+		t.Fatalf("error removing stray rows: %v", err)
+	}
+
+	if removed != 2 {
+		t.Errorf("expected 2 stray rows removed, got %d", removed)
+	}
+
+	lastStatus, err := queryLastStatus()
+
+	if err != nil || lastStatus.ID != 1 {
+		t.Errorf("expected the singleton to remain, got %+v, %v", lastStatus, err)
+	}
+}
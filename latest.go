@@ -0,0 +1,55 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/adampresley/httphelpers/responses"
+)
+
+/*
+LatestItemResponse is the newest feed item, trimmed down for widgets and
+bots that only want the current headline. Type is the item's severity.
+*/
+type LatestItemResponse struct {
+	ID          uint      `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Link        string    `json:"link"`
+	PubDate     time.Time `json:"pubDate"`
+	Type        string    `json:"type"`
+}
+
+/*
+latestJsonHandler returns the most recent feed item, or 204 No Content
+when the feed is empty.
+*/
+func latestJsonHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err  error
+			feed []*Feed
+		)
+
+		if feed, err = queryFeed(1); err != nil {
+			slog.Error("error querying latest feed item", "error", err)
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, "An unexpected error occurred while querying the latest feed item")
+			return
+		}
+
+		if len(feed) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		writeJsonOK(w, r, LatestItemResponse{
+			ID:          feed[0].ID,
+			Title:       feed[0].Title,
+			Description: feed[0].Description,
+			Link:        feed[0].GetLink(),
+			PubDate:     feed[0].PubDate,
+			Type:        feed[0].GetSeverity(),
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLatestJsonHandler(t *testing.T) {
+	var (
+		latest LatestItemResponse
+	)
+
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	w := httptest.NewRecorder()
+	latestJsonHandler()(w, httptest.NewRequest(http.MethodGet, "/latest.json", nil))
+
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Errorf("expected an empty 204 before any items, got %d: %s", w.Code, w.Body.String())
+	}
+
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, testPageOutage)
+
+	w = httptest.NewRecorder()
+	latestJsonHandler()(w, httptest.NewRequest(http.MethodGet, "/latest.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	if err := json.Unmarshal(w.Body.Bytes(), &latest); err != nil {
+		t.Fatalf("error decoding latest item: %v", err)
+	}
+
+	feed := queryTestFeed(t)
+
+	if latest.ID != feed[0].ID || latest.Title != "1 services reporting potential issues" {
+		t.Errorf("expected the newest item, got %+v", latest)
+	}
+
+	if latest.Type != feed[0].GetSeverity() || latest.Link != config.StatusPageURL || latest.PubDate.IsZero() {
+		t.Errorf("unexpected latest item fields %+v", latest)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"gorm.io/gorm"
+)
+
+/*
+LegendEntry is a single icon class and the status label the status
+page's legend gives it.
+*/
+type LegendEntry struct {
+	Status    string
+	ClassName string
+}
+
+/*
+bootstrapStatusesFromLegend fetches the status page, reads its legend, and
+reconciles the Status table against it. Known labels whose class name has
+drifted are updated, and unknown labels are added. Any label other than
+"Operational" is treated as an error. The refreshed status list is returned.
+*/
+func bootstrapStatusesFromLegend(statuses []*Status) ([]*Status, error) {
+	var (
+		err       error
+		doc       *goquery.Document
+		entries   []LegendEntry
+		refreshed []*Status
+	)
+
+	if doc, _, err = grabStatusPage(config.StatusPageURL, PageValidators{}); err != nil {
+		return statuses, fmt.Errorf("error grabbing status page to read legend: %w", err)
+	}
+
+	if entries = parseLegend(doc); len(entries) == 0 {
+		return statuses, fmt.Errorf("no legend entries found using selector '%s'", config.LegendSelector)
+	}
+
+	for _, entry := range entries {
+		existing := findStatusByLabel(statuses, entry.Status)
+
+		if existing == nil {
+			slog.Info("adding status discovered in legend", "status", entry.Status, "className", entry.ClassName)
+
+			if err = insertStatus(&Status{
+				Status:    entry.Status,
+				ClassName: entry.ClassName,
+				IsError:   !strings.EqualFold(entry.Status, "Operational"),
+			}); err != nil {
+				return statuses, err
+			}
+
+			continue
+		}
+
+		if existing.ClassName != entry.ClassName {
+			slog.Warn("legend class name differs from configured status. updating", "status", entry.Status, "configured", existing.ClassName, "legend", entry.ClassName)
+
+			if err = updateStatusClassName(existing.ID, entry.ClassName); err != nil {
+				return statuses, err
+			}
+		}
+	}
+
+	if refreshed, err = queryStatuses(); err != nil {
+		return statuses, err
+	}
+
+	return refreshed, nil
+}
+
+/*
+parseLegend returns the class name and label of every legend icon. The
+label is the text of the icon's parent element, and the class name is the
+icon's first "text-" class, matching how status icons are styled.
+*/
+func parseLegend(doc *goquery.Document) []LegendEntry {
+	result := []LegendEntry{}
+
+	doc.Find(config.LegendSelector).Each(func(i int, s *goquery.Selection) {
+		label := strings.TrimSpace(s.Parent().Text())
+		className := ""
+
+		for class := range strings.FieldsSeq(s.AttrOr("class", "")) {
+			if strings.HasPrefix(class, "text-") {
+				className = class
+				break
+			}
+		}
+
+		if label == "" || className == "" {
+			return
+		}
+
+		result = append(result, LegendEntry{Status: label, ClassName: className})
+	})
+
+	return result
+}
+
+func findStatusByLabel(statuses []*Status, label string) *Status {
+	for _, status := range statuses {
+		if strings.EqualFold(status.Status, label) {
+			return status
+		}
+	}
+
+	return nil
+}
+
+func insertStatus(status *Status) error {
+	var (
+		err error
+	)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if err = gorm.G[Status](db).Create(ctx, status); err != nil {
+		return fmt.Errorf("error inserting status '%s': %w", status.Status, err)
+	}
+
+	return nil
+}
+
+func updateStatusClassName(id uint, className string) error {
+	var (
+		err error
+	)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if _, err = gorm.G[Status](db).Where("id=?", id).Update(ctx, "class_name", className); err != nil {
+		return fmt.Errorf("error updating class name for status %d: %w", id, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBootstrapStatusesFromLegend(t *testing.T) {
+	setupTestDB(t)
+	_, statuses := seedTestStatuses(t)
+
+	config.LegendSelector = "div.legend i"
+
+	/*
+	 * "Outage" has moved to a new class, and "Maintenance" is new.
+	 */
+	serveStatusPage(t, `<html><body><div class="legend">
+		<span><i class="icon text-operational ok"></i> Operational</span>
+		<span><i class="text-outage"></i> Outage</span>
+		<span><i class="text-maintenance"></i> Maintenance</span>
+		<span><i class="no-prefix"></i> Ignored</span>
+	</div></body></html>`)
+
+	refreshed, err := bootstrapStatusesFromLegend(statuses)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]struct {
+		className string
+		isError   bool
+	}{
+		"Operational": {className: "text-operational", isError: false},
+		"Outage":      {className: "text-outage", isError: true},
+		"Maintenance": {className: "text-maintenance", isError: true},
+	}
+
+	if len(refreshed) != len(want) {
+		t.Fatalf("expected %d statuses, got %d", len(want), len(refreshed))
+	}
+
+	for _, status := range refreshed {
+		w, ok := want[status.Status]
+
+		if !ok || w.className != status.ClassName || w.isError != status.IsError {
+			t.Errorf("unexpected status %s (%s, error %v)", status.Status, status.ClassName, status.IsError)
+		}
+	}
+}
+
+func TestBootstrapStatusesFromLegendWithoutLegend(t *testing.T) {
+	setupTestDB(t)
+	_, statuses := seedTestStatuses(t)
+
+	config.LegendSelector = "div.legend i"
+	serveStatusPage(t, testPageOperational)
+
+	refreshed, err := bootstrapStatusesFromLegend(statuses)
+
+	if err == nil {
+		t.Fatalf("expected an error for a page without a legend")
+	}
+
+	if len(refreshed) != len(statuses) {
+		t.Errorf("expected the statuses to be returned unchanged")
+	}
+}
@@ -2,16 +2,23 @@ package main
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
+	"flag"
 	"fmt"
+	"html"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"runtime/debug"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,6 +31,37 @@ import (
 	"github.com/hanagantig/cron"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	feedTitle       = "Shopify Services Status"
+	feedDescription = "Providing the current status of Shopify services through RSS!"
+	feedGenerator   = "shopify-status-rss by Adam Presley"
+
+	SeverityInfo     = "info"
+	SeverityRecovery = "recovery"
+	SeverityMinor    = "minor"
+	SeverityMajor    = "major"
+
+	feedFormatRss  = "rss"
+	feedFormatAtom = "atom"
+	feedFormatJson = "json"
+
+	serviceNameSelector = "div.flex-col > p"
+	statusIconSelector  = "div.flex-col i"
+
+	HashModeFull      = "full"
+	HashModeErrorOnly = "error-only"
+
+	ItemGranularityCombined   = "combined"
+	ItemGranularityPerService = "per-service"
+
+	FirstRunItemBaseline     = "baseline"
+	FirstRunItemCurrentState = "current-state"
+	FirstRunItemNone         = "none"
+
+	checkStatusLockKey = "check-status"
 )
 
 var (
@@ -31,8 +69,25 @@ var (
 
 	config               *Config
 	db                   *gorm.DB
+	readDB               *gorm.DB
 	aleticsClientOptions *clientoptions.ClientOptions
 	useAletics           bool = false
+
+	ErrPageNotModified    = errors.New("status page not modified")
+	ErrPageTooLarge       = errors.New("status page exceeds the maximum allowed size")
+	ErrDuplicateService   = errors.New("service appears more than once on the status page")
+	ErrIncompleteStatus   = errors.New("parsed status is incomplete")
+	ErrUnexpectedRedirect = errors.New("status page redirected to an unexpected host")
+	ErrCronLockInUse      = errors.New("cannot obtain cron lock")
+	ErrUnknownStatusClass = errors.New("status icon matches no known status class")
+	ErrPageEmpty          = errors.New("status page was parsed but its root container is missing")
+	ErrPageRequiresJS     = errors.New("status page contains no service entries. it likely requires JavaScript rendering; consider reading status from a JSON API instead")
+
+	feedRenderers = map[string]func([]*Feed) ([]byte, error){
+		feedFormatRss:  renderRssFeed,
+		feedFormatAtom: renderAtomFeed,
+		feedFormatJson: renderJsonFeed,
+	}
 )
 
 /*
@@ -41,20 +96,33 @@ Database models
 *******************************************************
 */
 type LastStatus struct {
-	ID             uint      `gorm:"primaryKey"`
-	UpdatedAt      time.Time `json:"updatedAt"`
-	LastStatusHash string    `json:"lastStatusHash"`
+	ID              uint      `gorm:"primaryKey"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+	LastStatusHash  string    `json:"lastStatusHash"`
+	ETag            string    `gorm:"column:etag" json:"etag"`
+	LastModified    string    `json:"lastModified"`
+	ChangedAt       time.Time `json:"changedAt"`
+	FollowUpSent    bool      `json:"followUpSent"`
+	LastSuccessAt   time.Time `json:"lastSuccessAt"`
+	Snapshot        string    `json:"snapshot"`
+	ParseFailures   int       `json:"parseFailures"`
+	BrokenNotified  bool      `json:"brokenNotified"`
+	PageUpdatedAt   time.Time `json:"pageUpdatedAt"`
+	OutageStartedAt time.Time `json:"outageStartedAt"`
+	EscalationSent  bool      `json:"escalationSent"`
 }
 
 type Service struct {
 	gorm.Model
-	ServiceName string `json:"serviceName"`
+	ServiceName string `gorm:"uniqueIndex" json:"serviceName"`
+	Active      bool   `gorm:"default:true" json:"active"`
+	MissingRuns int    `json:"missingRuns"`
 }
 
 type Status struct {
 	gorm.Model
 	Status    string `json:"status"`
-	ClassName string `json:"className"`
+	ClassName string `gorm:"uniqueIndex" json:"className"`
 	IsError   bool   `json:"isError"`
 }
 
@@ -75,6 +143,10 @@ type Feed struct {
 	Title       string    `json:"title" xml:"title"`
 	PubDate     time.Time `json:"pubDate" xml:"pubDate"`
 	Description string    `json:"description" xml:"description"`
+	Severity    string    `json:"severity" xml:"-"`
+	Link        string    `json:"link" xml:"-"`
+	ContentHash string    `gorm:"index" json:"-" xml:"-"`
+	Snapshot    string    `json:"-" xml:"-"`
 }
 
 type CronLock struct {
@@ -82,6 +154,28 @@ type CronLock struct {
 	Key string
 }
 
+type FeedCache struct {
+	ID              uint      `gorm:"primaryKey"`
+	CreatedAt       time.Time `json:"createdAt"`
+	Format          string    `gorm:"uniqueIndex:idx_feed_cache_key" json:"format"`
+	ItemLimit       int       `gorm:"uniqueIndex:idx_feed_cache_key" json:"itemLimit"`
+	ETag            string    `gorm:"column:etag" json:"etag"`
+	NewestAt        time.Time `json:"newestAt"`
+	NewestFeedID    uint      `json:"newestFeedId"`
+	FeedCount       int64     `json:"feedCount"`
+	NewestUpdatedAt string    `json:"newestUpdatedAt"`
+	StatusHash      string    `json:"statusHash"`
+	Body            []byte    `json:"-"`
+}
+
+type StatusTransition struct {
+	gorm.Model
+	PreviousHash     string `json:"previousHash"`
+	NewHash          string `json:"newHash"`
+	AffectedServices string `json:"affectedServices"`
+	FeedID           uint   `json:"feedId"`
+}
+
 /*
 *******************************************************
 App models
@@ -90,31 +184,87 @@ App models
 type ParsedStatus struct {
 	Service *Service
 	Status  *Status
+	Group   string
+	Muted   bool
 }
 
 type ParsedStatusCollection []ParsedStatus
 
+/*
+ServiceState is the persisted form of a single parsed service status. The
+last parsed collection is stored as a JSON array of these in
+LastStatus.Snapshot so the next run can tell what changed.
+*/
+type ServiceState struct {
+	ServiceName string `json:"serviceName"`
+	Group       string `json:"group,omitempty"`
+	Status      string `json:"status"`
+	ClassName   string `json:"className"`
+	IsError     bool   `json:"isError"`
+	Muted       bool   `json:"muted,omitempty"`
+}
+
+/*
+PageValidators holds the cache validators returned by the status page
+so subsequent fetches can be made conditional.
+*/
+type PageValidators struct {
+	ETag         string
+	LastModified string
+}
+
 type RssFeed struct {
-	XMLName xml.Name   `xml:"rss"`
-	Version string     `xml:"version,attr"`
-	AtomNS  string     `xml:"xmlns:atom,attr"`
-	Channel RssChannel `xml:"channel"`
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	DcNS      string     `xml:"xmlns:dc,attr,omitempty"`
+	ContentNS string     `xml:"xmlns:content,attr,omitempty"`
+	SsrNS     string     `xml:"xmlns:ssr,attr,omitempty"`
+	Channel   RssChannel `xml:"channel"`
 }
 
 type RssChannel struct {
-	Title       string    `xml:"title"`
-	Link        string    `xml:"link"`
-	Description string    `xml:"description"`
-	Language    string    `xml:"language"`
-	Generator   string    `xml:"generator"`
-	Items       []RssItem `xml:"item"`
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Description string        `xml:"description"`
+	Language    string        `xml:"language"`
+	Generator   string        `xml:"generator"`
+	SkipHours   *RssSkipHours `xml:"skipHours,omitempty"`
+	SkipDays    *RssSkipDays  `xml:"skipDays,omitempty"`
+	Items       []RssItem     `xml:"item"`
 }
 
 type RssItem struct {
-	Title       string    `xml:"title"`
-	Link        string    `xml:"link"`
-	Description string    `xml:"description"`
-	PubDate     time.Time `xml:"pubDate"`
+	Title       string       `xml:"title"`
+	Link        string       `xml:"link"`
+	Description string       `xml:"description"`
+	PubDate     time.Time    `xml:"pubDate"`
+	Creator     string       `xml:"dc:creator,omitempty"`
+	Content     *RssCData    `xml:"content:encoded,omitempty"`
+	Services    []RssService `xml:"ssr:service,omitempty"`
+	Severity    string       `xml:"-"`
+	Snapshot    string       `xml:"-"`
+	Metadata    string       `xml:"-"`
+}
+
+/*
+RssCData holds HTML written as a CDATA section. encoding/xml splits any
+"]]>" in the body across sections, so the HTML needs no other escaping.
+*/
+type RssCData struct {
+	Body string `xml:",cdata"`
+}
+
+/*
+CronResult summarizes a single status check. AffectedServices lists the
+services in error after a change.
+*/
+type CronResult struct {
+	Changed          bool     `json:"changed"`
+	HasErrors        bool     `json:"hasErrors"`
+	ItemWritten      bool     `json:"itemWritten"`
+	AffectedServices []string `json:"affectedServices"`
+	Err              error    `json:"-"`
 }
 
 type AleticsPayload struct {
@@ -132,37 +282,68 @@ Main
 func main() {
 	var (
 		err      error
-		dialect  gorm.Dialector
 		statuses []*Status
 		services []*Service
+		removed  int
 	)
 
 	config = LoadConfig()
 	setupLogging()
-	shutdownCtx, stopApp := context.WithCancel(context.Background())
+
+	if err = validateConfig(config); err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
+	trustedProxies = parseTrustedProxies(config.TrustedProxies)
+	notifiers = buildNotifiers(config)
+	escalationNotifiers = buildEscalationNotifiers(config)
 
 	/*
 	 * Database
 	 */
-	if strings.HasPrefix(config.DSN, "file:") {
-		dialect = sqlite.Open(config.DSN)
-	} else if strings.HasPrefix(config.DSN, "postgres:") || strings.HasPrefix(config.DSN, "postgresql:") {
-		dialect = postgres.Open(config.DSN)
-	} else {
-		panic("Unsupported database dialect")
-	}
-
-	if db, err = gorm.Open(dialect, &gorm.Config{}); err != nil {
+	if err = connectDatabase(); err != nil {
 		slog.Error("error connecting to database", "error", err)
 		os.Exit(1)
 	}
 
+	/*
+	 * verify is read-only and meant to run before a deploy, so it must
+	 * not migrate the database it is checking.
+	 */
+	if flag.Arg(0) == "verify" {
+		os.Exit(runVerify())
+	}
+
 	slog.Info("Database connection established. Running migrations...")
 
-	db.AutoMigrate(
-		&Service{}, &Status{}, &ServiceStatus{},
-		&Feed{}, &LastStatus{}, &CronLock{},
-	)
+	if err = runMigrations(); err != nil {
+		slog.Error("error running database migrations", "error", err)
+		os.Exit(1)
+	}
+
+	if config.MigrateOnly {
+		slog.Info("migrations complete. exiting")
+		return
+	}
+
+	if flag.Arg(0) == "seed" {
+		os.Exit(runSeed())
+	}
+
+	if removed, err = deleteStrayLastStatuses(); err != nil {
+		slog.Error("error removing stray last status rows", "error", err)
+	} else if removed > 0 {
+		slog.Warn("removed stray last status rows", "count", removed)
+	}
+
+	if !config.NoAutoSeed {
+		if err = seedDefaults(); err != nil {
+			slog.Error("error seeding default statuses and services", "error", err)
+		}
+	}
+
+	shutdownCtx, stopApp := context.WithCancel(context.Background())
 
 	if config.AleticsURL != "" && config.AleticsToken != "" {
 		useAletics = true
@@ -178,48 +359,148 @@ func main() {
 		panic("error querying statuses: " + err.Error())
 	}
 
+	if config.LearnStatusesFromLegend {
+		if statuses, err = bootstrapStatusesFromLegend(statuses); err != nil {
+			slog.Error("error learning statuses from the status page legend", "error", err)
+		}
+	}
+
 	if services, err = queryServices(); err != nil {
 		panic("error querying services: " + err.Error())
 	}
 
+	/*
+	 * Refusing to start has to happen before the server is up. Otherwise
+	 * the check only warns, so with AsyncInitialCheck it waits for the
+	 * background check rather than hold up startup.
+	 */
+	if config.FailOnSelectorMismatch || !config.AsyncInitialCheck {
+		if !runStartupSelectorCheck(services, statuses) {
+			os.Exit(1)
+		}
+	}
+
 	routes := []mux.Route{
 		{Path: "GET /status.rss", HandlerFunc: statusRssHandler()},
+		{Path: "GET /status.json", HandlerFunc: statusJsonHandler()},
+		{Path: "GET /feed", HandlerFunc: feedHandler()},
+		{Path: "GET /healthz", HandlerFunc: healthzHandler()},
+		{Path: "GET /current.json", HandlerFunc: currentJsonHandler()},
+		{Path: "GET /latest.json", HandlerFunc: latestJsonHandler()},
+		{Path: "GET /slo.json", HandlerFunc: sloJsonHandler()},
+		{Path: "GET /schema/current.json", HandlerFunc: schemaHandler("current.json")},
+		{Path: "GET /schema/feed.json", HandlerFunc: schemaHandler("feed.json")},
 	}
 
-	muxer := mux.Setup(
-		config,
-		routes,
-		shutdownCtx,
-		stopApp,
+	if config.AdminToken != "" {
+		routes = append(routes, adminRoutes()...)
+	}
 
-		mux.WithDebug(Version == "development"),
-		mux.WithMiddlewares(
-			func(h http.Handler) http.Handler {
-				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					slog.Info("request", "method", r.Method, "path", r.URL.Path)
-					h.ServeHTTP(w, r)
-				})
-			},
-		),
-	)
+	muxer := setupRouter(routes, shutdownCtx, stopApp)
 
-	postgresLocker := &PostgresLocker{DB: db}
+	postgresLocker := &PostgresLocker{
+		DB:          db,
+		RetryFor:    config.LockRetry,
+		MaxAttempts: config.LockMaxAttempts,
+	}
 
 	c := cron.New(
 		cron.WithLocks(postgresLocker),
 	)
 
-	c.AddFunc(config.CronSchedule, "check-status", func() {
-		cronJob(services, statuses)
+	/*
+	 * Services are queried on every run so ones disabled since startup
+	 * are left out.
+	 */
+	c.AddFunc(config.CronSchedule, checkStatusLockKey, func() {
+		var (
+			err             error
+			currentServices []*Service
+		)
+
+		if currentServices, err = queryServices(); err != nil {
+			slog.Error("error querying services. check skipped", "error", err)
+			return
+		}
+
+		safeCronJob(currentServices, statuses)
 	})
 
-	cronJob(services, statuses)
+	if config.MaintenanceSchedule != "" {
+		c.AddFunc(config.MaintenanceSchedule, "maintenance", maintenanceJob)
+	}
+
+	runInitialCheck(services, statuses)
 	c.Start()
 
-	slog.Info("server started", "host", config.Host, "schedule", config.CronSchedule, "statusPage", config.StatusPageURL, "version", Version)
+	slog.Info("server started", "host", config.Host, "dsn", redactDSN(config.DSN), "schedule", config.CronSchedule, "statusPage", config.StatusPageURL, "version", Version)
 	muxer.Start()
 }
 
+/*
+connectDatabase opens the primary database and, when Config.ReadDSN is
+set, a separate read-only connection used by the public read endpoints.
+Without a ReadDSN, readDB is the primary.
+*/
+func connectDatabase() error {
+	var (
+		err     error
+		dialect gorm.Dialector
+	)
+
+	if dialect, err = openDialector(config.DSN); err != nil {
+		return err
+	}
+
+	if db, err = gorm.Open(dialect, &gorm.Config{}); err != nil {
+		return err
+	}
+
+	readDB = db
+
+	if config.ReadDSN == "" {
+		return nil
+	}
+
+	if dialect, err = openDialector(config.ReadDSN); err != nil {
+		return fmt.Errorf("error opening read database: %w", err)
+	}
+
+	if readDB, err = gorm.Open(dialect, &gorm.Config{}); err != nil {
+		return fmt.Errorf("error opening read database: %w", err)
+	}
+
+	return nil
+}
+
+func openDialector(dsn string) (gorm.Dialector, error) {
+	if strings.HasPrefix(dsn, "file:") {
+		return sqlite.Open(dsn), nil
+	}
+
+	if strings.HasPrefix(dsn, "postgres:") || strings.HasPrefix(dsn, "postgresql:") {
+		return postgres.Open(dsn), nil
+	}
+
+	return nil, fmt.Errorf("unsupported database dialect")
+}
+
+func runMigrations() error {
+	var (
+		err error
+	)
+
+	if err = dedupeNaturalKeys(); err != nil {
+		return err
+	}
+
+	return db.AutoMigrate(
+		&Service{}, &Status{}, &ServiceStatus{},
+		&Feed{}, &LastStatus{}, &CronLock{},
+		&StatusTransition{}, &FeedCache{}, &MutedService{},
+	)
+}
+
 func setupLogging() {
 	var (
 		logger *slog.Logger
@@ -248,89 +529,407 @@ func setupLogging() {
 	slog.SetDefault(logger)
 }
 
-func cronJob(services []*Service, statuses []*Status) {
+/*
+setupRouter builds the HTTP server, applying the configured timeouts so
+slow clients can't hold connections open indefinitely.
+*/
+func setupRouter(routes []mux.Route, shutdownCtx context.Context, stopApp context.CancelFunc) *mux.Router {
+	return mux.Setup(
+		config,
+		routes,
+		shutdownCtx,
+		stopApp,
+
+		mux.WithDebug(Version == "development"),
+		mux.WithReadTimeout(config.ReadTimeout),
+		mux.WithWriteTimeout(config.WriteTimeout),
+		mux.WithIdleTimeout(config.IdleTimeout),
+		mux.WithMiddlewares(
+			func(h http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					slog.Info("request", "method", r.Method, "path", r.URL.Path, "ip", clientIP(r))
+					h.ServeHTTP(w, r)
+				})
+			},
+		),
+	)
+}
+
+/*
+runInitialCheck runs the first status check. With Config.AsyncInitialCheck
+it runs in the background, since a slow first fetch would otherwise delay
+the server accepting connections and fail readiness probes. The returned
+channel is closed once the check finishes.
+*/
+func runInitialCheck(services []*Service, statuses []*Status) <-chan struct{} {
+	done := make(chan struct{})
+
+	if !config.AsyncInitialCheck {
+		lockedInitialCheck(services, statuses)
+		close(done)
+		return done
+	}
+
+	go func() {
+		defer close(done)
+
+		if !config.FailOnSelectorMismatch {
+			runStartupSelectorCheck(services, statuses)
+		}
+
+		lockedInitialCheck(services, statuses)
+	}()
+
+	return done
+}
+
+/*
+lockedInitialCheck runs safeCronJob holding the same lock as the scheduled
+check, so an initial check still running when the first scheduled one
+fires, or one started alongside another replica's, is never run twice at
+once. The check is skipped if the lock is already held.
+*/
+func lockedInitialCheck(services []*Service, statuses []*Status) {
 	var (
-		err        error
-		doc        *goquery.Document
-		states     = ParsedStatusCollection{}
-		lastStatus *LastStatus
-		rssItem    RssItem
+		err error
 	)
 
-	if doc, err = grabStatusPage(config.StatusPageURL); err != nil {
-		slog.Error("error grabbing status page", "error", err)
+	ctx, cancel := getContext()
+	defer cancel()
+
+	locker := &PostgresLocker{DB: db}
+
+	if err = locker.Lock(ctx, checkStatusLockKey); err != nil {
+		if errors.Is(err, ErrCronLockInUse) {
+			slog.Info("a status check is already running. initial check skipped")
+			return
+		}
+
+		slog.Error("error obtaining cron lock for initial check. check skipped", "error", err)
 		return
 	}
 
-	if states, err = parsePageStatuses(doc, services, statuses); err != nil {
-		slog.Error("error parsing page statuses", "error", err)
-		return
+	defer func() {
+		ctx, cancel := getContext()
+		defer cancel()
+
+		if err := locker.Unlock(ctx, checkStatusLockKey); err != nil {
+			slog.Error("error releasing cron lock for initial check", "error", err)
+		}
+	}()
+
+	safeCronJob(services, statuses)
+}
+
+/*
+safeCronJob runs cronJob, recovering from any panic so that one bad check
+is logged with its stack instead of killing the scheduler's goroutine.
+*/
+func safeCronJob(services []*Service, statuses []*Status) (result CronResult) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			slog.Error("status check panicked", "panic", recovered, "stack", string(debug.Stack()))
+			result = CronResult{Err: fmt.Errorf("status check panicked: %v", recovered)}
+		}
+	}()
+
+	return cronJob(services, statuses)
+}
+
+/*
+cronJob checks the status page once, writing feed items for any change.
+The returned CronResult describes what happened so callers such as
+/admin/check can report it. Errors are also logged here.
+*/
+func cronJob(services []*Service, statuses []*Status) CronResult {
+	var (
+		err          error
+		doc          *goquery.Document
+		states       = ParsedStatusCollection{}
+		lastStatus   *LastStatus
+		validators   PageValidators
+		rssItem      RssItem
+		feedID       uint
+		incidentLink string
+		scrape       ScrapeMetadata
+		result       = CronResult{}
+	)
+
+	if lastStatus, err = queryLastStatus(); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		if lastStatus = cachedLastStatus(); lastStatus == nil {
+			slog.Error("error querying last status", "error", err)
+			result.Err = err
+			return result
+		}
+
+		slog.Warn("error querying last status. comparing against the status held in memory", "error", err)
+		err = nil
+	} else if err == nil {
+		lastStatus = reconcileUnsavedChanges(lastStatus)
+	}
+
+	isFirstRun := errors.Is(err, gorm.ErrRecordNotFound)
+
+	/*
+	 * A 304 is answered from the stored snapshot, so a row written before
+	 * snapshots were kept has to fetch the full page until it has one.
+	 */
+	if !isFirstRun && lastStatus.Snapshot != "" {
+		validators = PageValidators{ETag: lastStatus.ETag, LastModified: lastStatus.LastModified}
+	}
+
+	scrape = ScrapeMetadata{ScrapedAt: time.Now(), Source: cmp.Or(config.UpstreamFeedURL, config.StatusPageURL)}
+
+	if config.UpstreamFeedURL != "" {
+		if states, err = fetchUpstreamStatuses(config.UpstreamFeedURL, services, statuses); err != nil {
+			slog.Error("error reading upstream feed", "error", err)
+
+			if !isFirstRun {
+				recordParseFailure(lastStatus, err)
+			}
+
+			result.Err = err
+			return result
+		}
+
+		scrape.Latency = time.Since(scrape.ScrapedAt)
+	} else {
+		doc, validators, err = grabStatusPage(config.StatusPageURL, validators)
+		scrape.Latency = time.Since(scrape.ScrapedAt)
+
+		/*
+		 * An unchanged page still counts as a check. The stored snapshot
+		 * stands in for it so history, follow-ups, and escalations carry on.
+		 */
+		if errors.Is(err, ErrPageNotModified) {
+			slog.Info("status page not modified since last check")
+			states = statesFromSnapshot(parseSnapshot(lastStatus.Snapshot), services, statuses)
+		} else if err != nil {
+			slog.Error("error grabbing status page", "error", err)
+			result.Err = err
+			return result
+		} else {
+			services = reconcileMissingServices(doc, services)
+
+			if states, err = parsePageStatuses(doc, services, statuses); err != nil {
+				slog.Error("error parsing page statuses", "error", err)
+
+				if errors.Is(err, ErrUnknownStatusClass) {
+					reportNewStatusClasses(unknownStatusClasses(states))
+				}
+
+				if !isFirstRun {
+					recordParseFailure(lastStatus, err)
+				}
+
+				result.Err = err
+				return result
+			}
+		}
+	}
+
+	observeCheckLatency(time.Since(scrape.ScrapedAt))
+
+	if !isFirstRun && lastStatus.ParseFailures > 0 {
+		recordParseRecovery(lastStatus)
 	}
 
+	slog.Debug("parsed status page", "states", states)
+
+	if err = insertServiceStatusHistory(states); err != nil {
+		slog.Error("error recording service status history", "error", err)
+	}
+
+	states = applyServiceMutes(states)
+
 	hash := generateStatusHash(states)
+	result.HasErrors = states.HasErrors()
 
-	if lastStatus, err = queryLastStatus(); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		slog.Error("error querying last status", "error", err)
-		return
+	if config.PreferIncidentLinks && doc != nil {
+		incidentLink = parseIncidentLink(doc, config.StatusPageURL)
 	}
 
 	/*
 	 * We have no records. Make one
 	 */
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		if err = insertLastStatus(hash); err != nil {
+	if isFirstRun {
+		if err = insertLastStatus(hash, validators, states); err != nil {
 			slog.Error("error creating last status record", "error", err)
 		}
 
-		if states.HasErrors() {
-			rssItem = generateErrorFeedItem(states)
-		} else {
-			rssItem = generateOperationalFeedItem(states)
+		recordPageUpdatedAt(doc)
+
+		switch config.FirstRunItem {
+		case FirstRunItemNone:
+			slog.Info("recorded initial status. no feed item written", "hash", hash)
+
+		case FirstRunItemBaseline:
+			rssItem = generateBaselineFeedItem(states)
+			rssItem.Metadata = scrape.Comment()
+
+			if feedID, err = insertRssItem(rssItem); err != nil {
+				slog.Error("error inserting RSS item", "error", err)
+			}
+
+		default:
+			if states.HasErrors() {
+				rssItem = generateErrorFeedItem(states)
+				rssItem.Link = cmp.Or(incidentLink, rssItem.Link)
+			} else {
+				rssItem = generateOperationalFeedItem(states)
+				rssItem.Severity = SeverityInfo
+			}
+
+			rssItem.Metadata = scrape.Comment()
+
+			if feedID, err = insertRssItem(rssItem); err != nil {
+				slog.Error("error inserting RSS item", "error", err)
+			}
 		}
 
-		if err = insertRssItem(rssItem); err != nil {
-			slog.Error("error inserting RSS item", "error", err)
+		if err = insertStatusTransition("", hash, states, feedID); err != nil {
+			slog.Error("error recording status transition", "error", err)
 		}
 
-		return
+		result.Changed = true
+		result.ItemWritten = feedID != 0
+		result.AffectedServices = states.ErrorServiceNames()
+		return result
 	}
 
+	recordCheckSuccess()
+	recordPageUpdatedAt(doc)
+	trackOutage(lastStatus, states)
+	checkEscalation(lastStatus, states)
+
 	/*
 	 * If we do have a record, check to see if the hash has changed.
 	 * If it has, did it flip to an error state, or did it flip back to a normal state?
 	 */
 	if lastStatus.LastStatusHash == hash {
 		slog.Info("no changes detected in status page")
-		return
-	}
 
-	if err = updateLastStatus(hash); err != nil {
-		slog.Error("error updating last status record", "error", err)
-		return
+		/*
+		 * Validators for a changed page are only saved with the new hash,
+		 * so a failed update can't make the next check a 304.
+		 */
+		if validators.ETag != lastStatus.ETag || validators.LastModified != lastStatus.LastModified {
+			if err = updatePageValidators(validators); err != nil {
+				slog.Error("error updating status page validators", "error", err)
+			}
+		}
+
+		if states.HasErrors() {
+			checkStillDown(lastStatus, states, incidentLink, scrape)
+		}
+
+		return result
 	}
 
-	if states.HasErrors() {
-		slog.Info("status page has errors. writing to feed", "hash", hash)
-		rssItem = generateErrorFeedItem(states)
+	current := *lastStatus
+	current.LastStatusHash = hash
+	current.Snapshot = states.Snapshot()
+	current.ChangedAt = time.Now()
+	current.ETag = validators.ETag
+	current.LastModified = validators.LastModified
+
+	if err = updateLastStatus(hash, states, validators); err != nil {
+		if !config.BufferFailedWrites {
+			slog.Error("error updating last status record", "error", err)
+			result.Err = err
+			return result
+		}
+
+		slog.Error("error updating last status record. holding the change in memory until the database is reachable", "error", err)
+		cacheLastStatus(&current, true)
 	} else {
-		slog.Info("status page is back to normal. writing to feed", "hash", hash)
-		rssItem = generateOperationalFeedItem(states)
+		cacheLastStatus(&current, false)
 	}
 
-	if err = insertRssItem(rssItem); err != nil {
-		slog.Error("error inserting RSS item", "error", err)
+	if config.DynamicChannelDescription {
+		clearMemoryFeedCache()
 	}
-}
 
-/*
+	if !hasUnmutedChanges(parseSnapshot(lastStatus.Snapshot), states) {
+		slog.Info("only muted services changed. not writing to feed", "hash", hash)
+		return result
+	}
+
+	if !meetsMinFeedSeverity(parseSnapshot(lastStatus.Snapshot), states) {
+		slog.Info("status change is below the minimum feed severity. not writing to feed", "severity", states.Severity(), "minimum", config.MinFeedSeverity)
+	} else if config.ItemGranularity == ItemGranularityPerService {
+		feedID = insertPerServiceFeedItems(parseSnapshot(lastStatus.Snapshot), states, incidentLink, scrape)
+	} else {
+		if states.HasErrors() {
+			slog.Info("status page has errors. writing to feed", "hash", hash)
+			rssItem = generateErrorFeedItem(states)
+			rssItem.Link = cmp.Or(incidentLink, rssItem.Link)
+		} else {
+			slog.Info("status page is back to normal. writing to feed", "hash", hash)
+			rssItem = generateOperationalFeedItem(states)
+		}
+
+		rssItem.Metadata = scrape.Comment()
+
+		if feedID, err = insertRssItem(rssItem); err != nil {
+			slog.Error("error inserting RSS item", "error", err)
+		}
+	}
+
+	if err = insertStatusTransition(lastStatus.LastStatusHash, hash, states, feedID); err != nil {
+		slog.Error("error recording status transition", "error", err)
+	}
+
+	result.Changed = true
+	result.ItemWritten = feedID != 0
+	result.AffectedServices = states.ErrorServiceNames()
+	return result
+}
+
+/*
 *******************************************************
 Model functions
 *******************************************************
 */
 
+/*
+IsComplete reports whether both the service and its status are set. A
+partial parse can leave either nil, and incomplete entries are skipped by
+the collection methods and feed generators.
+*/
+func (ps ParsedStatus) IsComplete() bool {
+	return ps.Service != nil && ps.Status != nil
+}
+
+/*
+IsError reports whether the service is in error. A muted service never
+is, whatever its status.
+*/
+func (ps ParsedStatus) IsError() bool {
+	return ps.IsComplete() && ps.Status.IsError && !ps.Muted
+}
+
+/*
+Validate returns ErrIncompleteStatus naming the first entry missing its
+service or status.
+*/
+func (psc ParsedStatusCollection) Validate() error {
+	for i, status := range psc {
+		if status.Service == nil {
+			return fmt.Errorf("%w: entry %d has no service", ErrIncompleteStatus, i)
+		}
+
+		if status.Status == nil {
+			return fmt.Errorf("%w: %s has no status", ErrIncompleteStatus, status.Service.ServiceName)
+		}
+	}
+
+	return nil
+}
+
 func (psc ParsedStatusCollection) HasErrors() bool {
 	for _, status := range psc {
-		if status.Status.IsError {
+		if status.IsError() {
 			return true
 		}
 	}
@@ -338,6 +937,201 @@ func (psc ParsedStatusCollection) HasErrors() bool {
 	return false
 }
 
+func (psc ParsedStatusCollection) Errors() ParsedStatusCollection {
+	result := ParsedStatusCollection{}
+
+	for _, status := range psc {
+		if status.IsError() {
+			result = append(result, status)
+		}
+	}
+
+	return result
+}
+
+/*
+Groups returns the distinct, sorted group names in the collection. It is
+empty when the page was parsed without Config.GroupSelector.
+*/
+func (psc ParsedStatusCollection) Groups() []string {
+	result := []string{}
+
+	for _, status := range psc {
+		if status.Group != "" && !slices.Contains(result, status.Group) {
+			result = append(result, status.Group)
+		}
+	}
+
+	slices.Sort(result)
+	return result
+}
+
+/*
+MarshalJSON writes a parsed status in the same shape as ServiceState, so
+logs, snapshots, and JSON endpoints all share one representation.
+*/
+func (ps ParsedStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ps.ToServiceState())
+}
+
+func (ps ParsedStatus) ToServiceState() ServiceState {
+	result := ServiceState{
+		Group: ps.Group,
+	}
+
+	if ps.Service != nil {
+		result.ServiceName = ps.Service.ServiceName
+	}
+
+	if ps.Status != nil {
+		result.Status = ps.Status.Status
+		result.ClassName = ps.Status.ClassName
+		result.IsError = ps.IsError()
+	}
+
+	result.Muted = ps.Muted
+
+	return result
+}
+
+/*
+Snapshot serializes the collection for storage in LastStatus.Snapshot.
+*/
+func (psc ParsedStatusCollection) Snapshot() string {
+	var (
+		err error
+		b   []byte
+	)
+
+	if b, err = json.Marshal(psc); err != nil {
+		slog.Error("error marshalling status snapshot", "error", err)
+		return ""
+	}
+
+	return string(b)
+}
+
+func (psc ParsedStatusCollection) ErrorServiceNames() []string {
+	result := []string{}
+
+	for _, status := range psc {
+		if status.IsError() {
+			result = append(result, status.Service.ServiceName)
+		}
+	}
+
+	return result
+}
+
+/*
+statesFromSnapshot rebuilds a parsed collection from stored service
+states, for checks where the status page reported no change. Services no
+longer active are left out, and a class with no matching status keeps
+the stored label as matchStatus would.
+*/
+func statesFromSnapshot(snapshot []ServiceState, services []*Service, statuses []*Status) ParsedStatusCollection {
+	result := ParsedStatusCollection{}
+
+	for _, state := range snapshot {
+		serviceIndex := slices.IndexFunc(services, func(service *Service) bool { return service.ServiceName == state.ServiceName })
+
+		if serviceIndex < 0 {
+			continue
+		}
+
+		status := &Status{
+			Status:    state.Status,
+			ClassName: state.ClassName,
+			IsError:   state.IsError,
+		}
+
+		if statusIndex := slices.IndexFunc(statuses, func(s *Status) bool { return s.ClassName == state.ClassName }); statusIndex >= 0 {
+			status = statuses[statusIndex]
+		}
+
+		result = append(result, ParsedStatus{
+			Service: services[serviceIndex],
+			Status:  status,
+			Group:   state.Group,
+		})
+	}
+
+	return result
+}
+
+/*
+parseSnapshot decodes a stored snapshot. Records written before snapshots
+were stored decode to an empty slice.
+*/
+func parseSnapshot(snapshot string) []ServiceState {
+	var (
+		err    error
+		result []ServiceState
+	)
+
+	if snapshot == "" {
+		return []ServiceState{}
+	}
+
+	if err = json.Unmarshal([]byte(snapshot), &result); err != nil {
+		slog.Error("error decoding status snapshot", "error", err)
+		return []ServiceState{}
+	}
+
+	return result
+}
+
+/*
+Severity classifies the collection by the fraction of services reporting
+errors. At or above Config.MajorSeverityFraction it is a major outage,
+otherwise any error is minor. A collection without errors is a recovery.
+*/
+func (psc ParsedStatusCollection) Severity() string {
+	return severityForCounts(len(psc.ErrorServiceNames()), len(psc))
+}
+
+func severityForCounts(errorCount, total int) string {
+	if total == 0 || errorCount == 0 {
+		return SeverityRecovery
+	}
+
+	affected := float64(errorCount) / float64(total)
+
+	if affected >= config.MajorSeverityFraction {
+		return SeverityMajor
+	}
+
+	return SeverityMinor
+}
+
+/*
+GetSeverity returns the stored severity of the feed item. Items written
+before severity was recorded are classified by their title.
+*/
+func (f *Feed) GetSeverity() string {
+	if f.Severity != "" {
+		return f.Severity
+	}
+
+	if strings.HasPrefix(f.Title, "All services") {
+		return SeverityRecovery
+	}
+
+	return SeverityMinor
+}
+
+/*
+GetLink returns the item's own link, such as a specific incident page,
+falling back to the status page.
+*/
+func (f *Feed) GetLink() string {
+	if f.Link != "" {
+		return f.Link
+	}
+
+	return config.StatusPageURL
+}
+
 /*
 *******************************************************
 Handlers
@@ -347,109 +1141,635 @@ func statusRssHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
 			err  error
-			feed []*Feed
 			b    []byte
+			etag string
 		)
 
-		if err = postToAnalytics(r); err != nil {
-			slog.Error("error posting to analytics", "error", err)
-		}
+		if err = postToAnalytics(r); err != nil {
+			slog.Error("error posting to analytics", "error", err)
+		}
+
+		if b, etag, err = renderFeed(feedFormatRss, parseLimit(r, 10, 100)); err != nil {
+			slog.Error("error rendering RSS feed", "error", err)
+			responses.TextInternalServerError(w, "An unexpected error occurred while rendering the RSS feed")
+			return
+		}
+
+		if config.ValidateFeedOutput {
+			if err = validateFeedXml(b); err != nil {
+				slog.Error("rendered RSS feed failed validation", "error", err)
+				responses.TextInternalServerError(w, "An unexpected error occurred while rendering the RSS feed")
+				return
+			}
+		}
+
+		writeFeed(w, r, "application/xml", b, etag)
+	}
+}
+
+/*
+*******************************************************
+General functions
+*******************************************************
+*/
+func getContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Second*10)
+}
+
+/*
+parseLimit reads the "limit" query parameter. A missing, non-numeric,
+or non-positive value yields def, and anything above max is clamped to max.
+*/
+func parseLimit(r *http.Request, def, max int) int {
+	return parsePositiveIntParam(r, "limit", def, max)
+}
+
+/*
+parsePositiveIntParam reads a positive integer query parameter by the
+same rules as parseLimit.
+*/
+func parsePositiveIntParam(r *http.Request, name string, def, max int) int {
+	var (
+		err   error
+		value int
+	)
+
+	raw := r.URL.Query().Get(name)
+
+	if raw == "" {
+		return def
+	}
+
+	if value, err = strconv.Atoi(raw); err != nil || value < 1 {
+		return def
+	}
+
+	return min(value, max)
+}
+
+/*
+grabStatusPage fetches and parses the status page. When validators from a
+previous fetch are provided the request is made conditional, preferring
+If-None-Match and falling back to If-Modified-Since for upstreams that
+only send Last-Modified. A 304 response returns ErrPageNotModified.
+
+When Config.RenderServiceURL is set the page is fetched through that
+headless browser service instead, for status pages rendered by JavaScript.
+*/
+func grabStatusPage(url string, validators PageValidators) (*goquery.Document, PageValidators, error) {
+	var (
+		err      error
+		request  *http.Request
+		response *http.Response
+		body     io.Reader
+		doc      *goquery.Document
+	)
+
+	ctx, cancel := statusPageContext()
+	defer cancel()
+
+	if config.RenderServiceURL != "" {
+		if request, err = newRenderRequest(ctx, config.RenderServiceURL, url); err != nil {
+			return doc, validators, fmt.Errorf("error creating render request for status page '%s': %w", url, err)
+		}
+	} else {
+		if request, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil); err != nil {
+			return doc, validators, fmt.Errorf("error creating request for status page '%s': %w", url, err)
+		}
+
+		if err = applyStatusPageAuth(request, config.StatusPageAuth); err != nil {
+			return doc, validators, err
+		}
+
+		if validators.ETag != "" {
+			request.Header.Set("If-None-Match", validators.ETag)
+		} else if validators.LastModified != "" {
+			request.Header.Set("If-Modified-Since", validators.LastModified)
+		}
+	}
+
+	if response, err = http.DefaultClient.Do(request); err != nil {
+		return doc, validators, fmt.Errorf("error fetching status page '%s': %w", url, err)
+	}
+
+	defer response.Body.Close()
+
+	if config.RenderServiceURL == "" {
+		if err = checkRedirectHost(request.URL, response.Request.URL); err != nil {
+			return doc, validators, err
+		}
+	}
+
+	if response.StatusCode == http.StatusNotModified {
+		return doc, validators, ErrPageNotModified
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return doc, validators, fmt.Errorf("status page '%s' returned status code %d", url, response.StatusCode)
+	}
+
+	/*
+	 * Validators from a render service describe the rendered output, not
+	 * the status page, so they are not kept.
+	 */
+	validators = PageValidators{}
+
+	if config.RenderServiceURL == "" {
+		validators = PageValidators{
+			ETag:         response.Header.Get("ETag"),
+			LastModified: response.Header.Get("Last-Modified"),
+		}
+	}
+
+	if body, err = limitPageBody(response.Body, config.MaxPageBytes); err != nil {
+		return doc, validators, fmt.Errorf("error reading status page '%s': %w", url, err)
+	}
+
+	if doc, err = goquery.NewDocumentFromReader(body); err != nil {
+		return doc, validators, fmt.Errorf("error parsing status page '%s': %w", url, err)
+	}
+
+	/*
+	 * An error page or empty body parses without error, so check that the
+	 * page has the expected structure before treating it as the real one.
+	 */
+	if config.RootContainerSelector != "" && doc.Find(config.RootContainerSelector).Length() == 0 {
+		return doc, validators, fmt.Errorf("%w. status page '%s' has no element matching '%s'", ErrPageEmpty, url, config.RootContainerSelector)
+	}
+
+	return doc, validators, nil
+}
+
+/*
+checkRedirectHost returns ErrUnexpectedRedirect when redirects ended on a
+host other than the one requested, such as a captive portal or login
+page, unless that host is listed in Config.AllowedRedirectHosts.
+*/
+func checkRedirectHost(requested, final *url.URL) error {
+	if strings.EqualFold(requested.Hostname(), final.Hostname()) {
+		return nil
+	}
+
+	for _, host := range strings.Split(config.AllowedRedirectHosts, ",") {
+		if strings.EqualFold(strings.TrimSpace(host), final.Hostname()) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: requested '%s' but was redirected to '%s'", ErrUnexpectedRedirect, requested, final)
+}
+
+/*
+statusPageContext bounds fetching and reading the status page. A render
+service loads the page in a browser first, so it gets Config.RenderTimeout
+rather than the usual request timeout.
+*/
+func statusPageContext() (context.Context, context.CancelFunc) {
+	if config.RenderServiceURL != "" && config.RenderTimeout > 0 {
+		return context.WithTimeout(context.Background(), config.RenderTimeout)
+	}
+
+	return getContext()
+}
+
+/*
+newRenderRequest builds a request asking a headless render service (such
+as browserless's /content endpoint) to load pageURL and return the
+rendered HTML.
+*/
+func newRenderRequest(ctx context.Context, renderServiceURL, pageURL string) (*http.Request, error) {
+	var (
+		err     error
+		b       []byte
+		request *http.Request
+	)
+
+	if b, err = json.Marshal(map[string]string{"url": pageURL}); err != nil {
+		return request, err
+	}
+
+	if request, err = http.NewRequestWithContext(ctx, http.MethodPost, renderServiceURL, bytes.NewReader(b)); err != nil {
+		return request, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	return request, nil
+}
+
+/*
+limitPageBody reads at most maxBytes from body. If the body is larger
+than that, ErrPageTooLarge is returned rather than handing a truncated
+document to the parser. A maxBytes of zero or less disables the limit.
+*/
+func limitPageBody(body io.Reader, maxBytes int) (io.Reader, error) {
+	var (
+		err error
+		b   []byte
+	)
+
+	if maxBytes <= 0 {
+		return body, nil
+	}
+
+	if b, err = io.ReadAll(io.LimitReader(body, int64(maxBytes)+1)); err != nil {
+		return nil, err
+	}
+
+	if len(b) > maxBytes {
+		return nil, fmt.Errorf("%w (%d bytes)", ErrPageTooLarge, maxBytes)
+	}
+
+	return bytes.NewReader(b), nil
+}
+
+/*
+loadFeed queries the newest feed items, substituting the no-data
+placeholder for an empty feed when configured.
+*/
+func loadFeed(limit int) ([]*Feed, error) {
+	var (
+		err  error
+		feed []*Feed
+	)
+
+	if feed, err = queryFeed(limit); err != nil {
+		return feed, fmt.Errorf("error querying feed: %w", err)
+	}
+
+	if len(feed) == 0 && config.EmitNoDataItem {
+		feed = []*Feed{noDataFeedItem()}
+	}
+
+	return feed, nil
+}
+
+/*
+renderFeed loads and marshals the feed in the requested format, returning
+the body and its ETag. When Config.CacheFeedInDB is set the rendered feed
+is shared between replicas through the feed_caches table.
+*/
+func renderFeed(format string, limit int) ([]byte, string, error) {
+	var (
+		err        error
+		cached     *FeedCache
+		version    FeedVersion
+		useDBCache bool
+		feed       []*Feed
+		b          []byte
+	)
+
+	if config.FeedCacheTTL > 0 {
+		if b, etag, ok := getMemoryFeedCache(format, limit); ok {
+			return b, etag, nil
+		}
+	}
+
+	/*
+	 * The version is read before the feed so a rendering that races with a
+	 * new item is stored under the older version and never served.
+	 */
+	if config.CacheFeedInDB {
+		if version, err = queryFeedVersion(); err != nil {
+			slog.Error("error querying feed version", "error", err)
+		} else {
+			useDBCache = true
+		}
+	}
+
+	lastSuccessAt := lastCheckSuccessAt()
+	stale := isFeedStale(lastSuccessAt)
+
+	if useDBCache && !stale {
+		cached, err = queryFeedCache(format, limit)
+
+		if err == nil && cached.Matches(version) {
+			return cached.Body, cached.ETag, nil
+		}
+
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Error("error querying feed cache", "format", format, "limit", limit, "error", err)
+		}
+	}
+
+	if feed, err = loadFeed(limit); err != nil {
+		return b, "", err
+	}
+
+	newestAt := newestPubDate(feed)
+
+	if stale {
+		feed = append([]*Feed{staleBannerFeedItem(lastSuccessAt)}, feed...)
+	}
+
+	if b, err = feedRenderers[format](feed); err != nil {
+		return b, "", fmt.Errorf("error marshalling %s feed: %w", format, err)
+	}
+
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(b))
+
+	/*
+	 * A lagging replica could otherwise cache an outdated feed until the
+	 * next item is written.
+	 */
+	if useDBCache && !stale && config.ReadDSN == "" {
+		if err = upsertFeedCache(format, limit, b, etag, newestAt, version); err != nil {
+			slog.Error("error storing feed cache", "format", format, "limit", limit, "error", err)
+		}
+	}
+
+	if config.FeedCacheTTL > 0 {
+		putMemoryFeedCache(format, limit, b, etag, config.FeedCacheTTL)
+	}
+
+	return b, etag, nil
+}
+
+func renderRssFeed(feed []*Feed) ([]byte, error) {
+	var (
+		err error
+		b   []byte
+	)
+
+	result := RssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: RssChannel{
+			Title:       feedTitle,
+			Link:        config.StatusPageURL,
+			Description: channelDescription(),
+			Language:    "en",
+			Generator:   feedGenerator,
+			SkipHours:   parseSkipHours(config.SkipHours),
+			SkipDays:    parseSkipDays(config.SkipDays),
+			Items:       []RssItem{},
+		},
+	}
+
+	/*
+	 * RSS <author> must be an email address, so the author is attributed
+	 * with Dublin Core's <dc:creator> instead.
+	 */
+	if config.FeedAuthor != "" {
+		result.DcNS = "http://purl.org/dc/elements/1.1/"
+	}
+
+	/*
+	 * Some readers prefer the full HTML of <content:encoded> over
+	 * <description>, so it is written alongside it when enabled.
+	 */
+	if config.UseContentEncoded {
+		result.ContentNS = "http://purl.org/rss/1.0/modules/content/"
+	}
+
+	if config.IncludeStructuredServices {
+		result.SsrNS = ssrNamespace
+	}
+
+	for _, f := range feed {
+		item := RssItem{
+			Title:       f.Title,
+			Link:        f.GetLink(),
+			Description: f.Description,
+			PubDate:     f.PubDate,
+			Creator:     config.FeedAuthor,
+		}
+
+		if config.UseContentEncoded {
+			item.Content = &RssCData{Body: f.Description}
+		}
+
+		if config.IncludeStructuredServices {
+			item.Services = structuredServices(f.Snapshot)
+		}
+
+		result.Channel.Items = append(result.Channel.Items, item)
+	}
+
+	if b, err = xml.Marshal(result); err != nil {
+		return b, err
+	}
+
+	return append([]byte(xml.Header), b...), nil
+}
+
+/*
+writeFeed sends a rendered feed with its ETag, answering with a 304 when
+the client already has the current version.
+*/
+func writeFeed(w http.ResponseWriter, r *http.Request, contentType string, b []byte, etag string) {
+	w.Header().Set("ETag", etag)
+
+	if config.AffectedServicesHeader {
+		setAffectedServicesHeader(w)
+	}
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	responses.Bytes(w, http.StatusOK, contentType, b)
+}
+
+func newestPubDate(feed []*Feed) time.Time {
+	var (
+		result time.Time
+	)
+
+	for _, f := range feed {
+		if f.ID != 0 && f.PubDate.After(result) {
+			result = f.PubDate
+		}
+	}
+
+	return result
+}
+
+/*
+lastCheckSuccessAt returns when a status check last succeeded. It is
+only read when Config.StaleBannerAge is set, and is the zero time before
+the first check or when the last status can't be read.
+*/
+func lastCheckSuccessAt() time.Time {
+	var (
+		err        error
+		lastStatus *LastStatus
+	)
+
+	if config.StaleBannerAge <= 0 {
+		return time.Time{}
+	}
+
+	if lastStatus, err = queryLastStatus(); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Error("error querying last status for stale check", "error", err)
+		}
+
+		return time.Time{}
+	}
+
+	return lastStatus.LastSuccessAt
+}
+
+/*
+isFeedStale reports whether the last successful status check is older
+than Config.StaleBannerAge. A quiet status page writes no feed items, so
+the age of the newest item says nothing about whether checks are running.
+Before the first successful check the feed is never stale.
+*/
+func isFeedStale(lastSuccessAt time.Time) bool {
+	return config.StaleBannerAge > 0 && !lastSuccessAt.IsZero() && time.Since(lastSuccessAt) > config.StaleBannerAge
+}
+
+/*
+staleBannerFeedItem returns a synthetic item placed at the top of a feed
+when status checks have not succeeded for long enough that they may have
+stopped working.
+*/
+func staleBannerFeedItem(lastSuccessAt time.Time) *Feed {
+	asOf := lastSuccessAt.UTC().Format(time.RFC1123)
+
+	return &Feed{
+		Title:       fmt.Sprintf("Data as of %s — may be outdated", asOf),
+		PubDate:     time.Now().UTC(),
+		Description: fmt.Sprintf(`<p>The last successful status check was at %s. Status checks may not be running, so this feed may not reflect the current status of Shopify services.</p>`, asOf),
+		Severity:    SeverityInfo,
+	}
+}
+
+/*
+noDataFeedItem returns a placeholder served in place of an empty feed
+before the first check has written anything.
+*/
+func noDataFeedItem() *Feed {
+	return &Feed{
+		Title:       "Monitoring starting, no data yet",
+		PubDate:     time.Now().UTC(),
+		Description: `<p>Shopify status monitoring has started, but no status checks have completed yet.</p>`,
+		Severity:    SeverityInfo,
+	}
+}
+
+/*
+writeServiceList writes the services as an HTML list. When services were
+parsed with a group, each group gets its own heading and list, ordered by
+group name.
+
+If Config.MaxDescriptionLength is set, services are dropped from the end
+of the list until the description fits, and an "...and N more" note is
+written in their place. The list is always written as complete elements
+so the description remains valid HTML.
+*/
+func writeServiceList(description *strings.Builder, states ParsedStatusCollection) {
+	for shown := len(states); shown >= 0; shown-- {
+		list := renderServiceList(states[:shown], len(states)-shown)
 
-		if feed, err = queryFeed(10); err != nil {
-			responses.TextInternalServerError(w, "An unexpected error occurred while querying the feed")
+		if config.MaxDescriptionLength <= 0 || shown == 0 || description.Len()+len(list) <= config.MaxDescriptionLength {
+			description.WriteString(list)
 			return
 		}
+	}
+}
 
-		result := RssFeed{
-			Version: "2.0",
-			AtomNS:  "http://www.w3.org/2005/Atom",
-			Channel: RssChannel{
-				Title:       "Shopify Services Status",
-				Link:        config.StatusPageURL,
-				Description: "Providing the current status of Shopify services through RSS!",
-				Language:    "en",
-				Generator:   "shopify-status-rss by Adam Presley",
-				Items:       []RssItem{},
-			},
-		}
+func renderServiceList(states ParsedStatusCollection, hiddenCount int) string {
+	var (
+		list = strings.Builder{}
+	)
 
-		for _, f := range feed {
-			result.Channel.Items = append(result.Channel.Items, RssItem{
-				Title:       f.Title,
-				Link:        config.StatusPageURL,
-				Description: f.Description,
-				PubDate:     f.PubDate,
-			})
+	groups := states.Groups()
+
+	/*
+	 * Services outside any matched section have no group. They are listed
+	 * first, without a heading, so they aren't lost when grouping is on.
+	 */
+	if len(groups) == 0 || slices.ContainsFunc(states, func(status ParsedStatus) bool { return status.Group == "" }) {
+		fmt.Fprintf(&list, `<ul>`)
+
+		for _, status := range states {
+			if status.Group == "" {
+				writeServiceListItem(&list, status)
+			}
 		}
 
-		if b, err = xml.Marshal(result); err != nil {
-			responses.TextInternalServerError(w, "An unexpected error occurred while marshalling the RSS feed")
-			return
+		fmt.Fprintf(&list, `</ul>`)
+	}
+
+	for _, group := range groups {
+		fmt.Fprintf(&list, `<h3>%s</h3>`, html.EscapeString(group))
+		fmt.Fprintf(&list, `<ul>`)
+
+		for _, status := range states {
+			if status.Group == group {
+				writeServiceListItem(&list, status)
+			}
 		}
 
-		b = append([]byte(xml.Header), b...)
-		responses.Bytes(w, http.StatusOK, "application/xml", b)
+		fmt.Fprintf(&list, `</ul>`)
+	}
+
+	if hiddenCount > 0 {
+		fmt.Fprintf(&list, `<p>...and %d more</p>`, hiddenCount)
 	}
+
+	return list.String()
 }
 
 /*
-*******************************************************
-General functions
-*******************************************************
+writeServiceListItem writes a single service and its status as a list item.
+With Config.IncludeRawClassNames the matched icon class is appended as an
+HTML comment to help diagnose selector drift.
 */
-func getContext() (context.Context, context.CancelFunc) {
-	return context.WithTimeout(context.Background(), time.Second*10)
-}
+func writeServiceListItem(description *strings.Builder, status ParsedStatus) {
+	if !status.IsComplete() {
+		return
+	}
 
-func grabStatusPage(url string) (*goquery.Document, error) {
-	var (
-		err      error
-		response *http.Response
-		doc      *goquery.Document
-	)
+	suffix := ""
 
-	if response, err = http.Get(url); err != nil {
-		return doc, fmt.Errorf("error fetching status page '%s': %w", url, err)
+	if status.Muted {
+		suffix = " (muted)"
 	}
 
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return doc, fmt.Errorf("status page '%s' returned status code %d", url, response.StatusCode)
+	if config.IncludeRawClassNames {
+		suffix += fmt.Sprintf("<!-- className: %s -->", strings.ReplaceAll(status.Status.ClassName, "--", "- -"))
 	}
 
-	if doc, err = goquery.NewDocumentFromReader(response.Body); err != nil {
-		return doc, fmt.Errorf("error parsing status page '%s': %w", url, err)
+	fmt.Fprintf(description, `<li>%s - %s%s</li>`, status.Service.ServiceName, status.Status.Status, suffix)
+}
+
+/*
+writeSummaryLine writes a one-line count of affected services, such as
+"3 of 15 services affected", when Config.IncludeSummaryLine is set. It
+comes first so reader previews show it.
+*/
+func writeSummaryLine(description *strings.Builder, states ParsedStatusCollection) {
+	if !config.IncludeSummaryLine {
+		return
 	}
 
-	return doc, nil
+	fmt.Fprintf(description, `<p><strong>%d of %d services affected</strong></p>`, len(states.Errors()), len(states))
 }
 
 func generateErrorFeedItem(states ParsedStatusCollection) RssItem {
 	var (
-		description             = strings.Builder{}
-		servicesWithIssuesCount = 0
+		description = strings.Builder{}
 	)
 
+	servicesWithIssues := states.Errors()
+
+	writeSummaryLine(&description, states)
 	fmt.Fprintf(&description, `<h2>Shopify Reports Issues</h2>`)
 	fmt.Fprintf(&description, `<p>The Shopify status page may be reporting issues. The 
 		following services are experiencing problems:</p>`)
-	fmt.Fprintf(&description, `<ul>`)
-
-	for _, status := range states {
-		if status.Status.IsError {
-			fmt.Fprintf(&description, `<li>%s - %s</li>`, status.Service.ServiceName, status.Status.Status)
-			servicesWithIssuesCount++
-		}
-	}
-
-	fmt.Fprintf(&description, `</ul>`)
+	writeServiceList(&description, servicesWithIssues)
 
 	result := RssItem{
-		Title:       fmt.Sprintf("%d services reporting potential issues", servicesWithIssuesCount),
-		Link:        "https://my.shopifystatus.com",
+		Title:       fmt.Sprintf("%d services reporting potential issues", len(servicesWithIssues)),
+		Link:        config.StatusPageURL,
 		Description: description.String(),
 		PubDate:     time.Now().UTC(),
+		Severity:    states.Severity(),
+		Snapshot:    states.Snapshot(),
 	}
 
 	return result
@@ -460,78 +1780,170 @@ func generateOperationalFeedItem(states ParsedStatusCollection) RssItem {
 		description = strings.Builder{}
 	)
 
+	writeSummaryLine(&description, states)
 	fmt.Fprintf(&description, `<h2>Shopify Is Operational</h2>`)
 	fmt.Fprintf(&description, `<p>The Shopify status page shows that all services appear to be operational.</p>`)
-	fmt.Fprintf(&description, `<ul>`)
-
-	for _, status := range states {
-		fmt.Fprintf(&description, `<li>%s - %s</li>`, status.Service.ServiceName, status.Status.Status)
+	writeServiceList(&description, states)
 
-		if status.Status.IsError {
-		}
+	result := RssItem{
+		Title:       "All services appear to be operational",
+		Link:        config.StatusPageURL,
+		Description: description.String(),
+		PubDate:     time.Now().UTC(),
+		Severity:    SeverityRecovery,
+		Snapshot:    states.Snapshot(),
 	}
 
-	fmt.Fprintf(&description, `</ul>`)
+	return result
+}
+
+/*
+generateBaselineFeedItem describes the state found when monitoring
+starts. Unlike the error and operational items it does not read as a
+change, since there is nothing earlier to compare against.
+*/
+func generateBaselineFeedItem(states ParsedStatusCollection) RssItem {
+	var (
+		description = strings.Builder{}
+	)
+
+	fmt.Fprintf(&description, `<h2>Monitoring Started</h2>`)
+	fmt.Fprintf(&description, `<p>Monitoring of the Shopify status page has started. %d of %d services 
+		are currently reporting issues. Future items will describe changes from this state.</p>`, len(states.Errors()), len(states))
+	writeServiceList(&description, states)
 
 	result := RssItem{
-		Title:       "All services appear to be operational",
-		Link:        "https://my.shopifystatus.com",
+		Title:       "Monitoring started",
+		Link:        config.StatusPageURL,
 		Description: description.String(),
 		PubDate:     time.Now().UTC(),
+		Severity:    SeverityInfo,
+		Snapshot:    states.Snapshot(),
 	}
 
 	return result
 }
 
+/*
+generateContentHash fingerprints what a subscriber sees of a feed item,
+ignoring its timestamp, for Config.DedupeWindow.
+*/
+func generateContentHash(item RssItem) string {
+	hasher := sha256.New()
+	fmt.Fprintf(hasher, "%s\n%s\n%s", item.Title, item.Link, item.Description)
+	return fmt.Sprintf("%x", hasher.Sum(nil))
+}
+
+/*
+generateStatusHash fingerprints the parsed statuses so changes can be
+detected between runs. In the "error-only" Config.HashMode only services
+reporting an error contribute, so cosmetic changes to non-error icon
+classes do not register as a transition.
+*/
 func generateStatusHash(parsedStatuses []ParsedStatus) string {
 	hasher := sha256.New()
 
 	for _, status := range parsedStatuses {
+		if !status.IsComplete() {
+			continue
+		}
+
+		if config.HashMode == HashModeErrorOnly && !status.Status.IsError {
+			continue
+		}
+
 		fmt.Fprintf(hasher, "%s:%s", status.Service.ServiceName, status.Status.ClassName)
+
+		/*
+		 * Muting changes the hash so a mute expiring on a service still in
+		 * error is noticed. hasUnmutedChanges keeps other mute changes out
+		 * of the feed.
+		 */
+		if status.Muted {
+			fmt.Fprintf(hasher, ":muted")
+		}
 	}
 
 	result := hasher.Sum(nil)
 	return fmt.Sprintf("%x", result)
 }
 
+/*
+parsePageStatuses pairs each known service on the status page with the
+status icon at the same position. Only the given services are expected,
+so entries for disabled or unknown services on the page are skipped
+without shifting the pairing.
+*/
 func parsePageStatuses(doc *goquery.Document, services []*Service, statuses []*Status) (ParsedStatusCollection, error) {
 	var (
-		result = ParsedStatusCollection{}
+		err           error
+		result        = ParsedStatusCollection{}
+		duplicates    = []string{}
+		pagePositions = []int{}
 	)
 
 	wantServiceCount := len(services)
 	gotCount := 0
 
-	doc.Find("div.flex-col > p").Each(func(i int, s *goquery.Selection) {
+	serviceNames := doc.Find(serviceNameSelector)
+
+	if config.DebugSelections {
+		logMatchedSelections(doc)
+	}
+
+	/*
+	 * A page rendered client-side arrives as an empty shell. Say so,
+	 * rather than reporting a confusing count mismatch.
+	 */
+	if serviceNames.Length() == 0 {
+		return result, ErrPageRequiresJS
+	}
+
+	serviceNames.Each(func(i int, s *goquery.Selection) {
 		for _, service := range services {
 			if service.ServiceName == s.Text() {
+				if slices.ContainsFunc(result, func(ps ParsedStatus) bool { return ps.Service == service }) {
+					duplicates = append(duplicates, service.ServiceName)
+				}
+
 				gotCount++
-				result = append(result, ParsedStatus{Service: service})
+				result = append(result, ParsedStatus{Service: service, Group: parseGroupName(s)})
+				pagePositions = append(pagePositions, i)
 				return
 			}
 		}
 	})
 
+	/*
+	 * Two entries with the same name would both pass the count check while
+	 * pairing icons with the wrong services.
+	 */
+	if len(duplicates) > 0 {
+		return result, fmt.Errorf("%w: %s", ErrDuplicateService, strings.Join(duplicates, ", "))
+	}
+
 	if gotCount != wantServiceCount {
 		return result, fmt.Errorf("the number of services on the page does not match the number of services in the database. something has changed")
 	}
 
-	gotCount = 0
+	icons := doc.Find(statusIconSelector)
 
-	doc.Find("div.flex-col i").Each(func(i int, s *goquery.Selection) {
-		for _, status := range statuses {
-			if s.HasClass(status.ClassName) {
-				if i < wantServiceCount {
-					gotCount++
-					result[i].Status = status
-					return
-				}
-			}
+	if icons.Length() < serviceNames.Length() {
+		return result, fmt.Errorf("the number of status icons on the page does not match the number of services on the page. something has changed")
+	}
+
+	for i, position := range pagePositions {
+		result[i].Status = matchStatus(icons.Eq(position), statuses)
+	}
+
+	if config.StrictStatusMatching {
+		if unknown := unknownStatusClasses(result); len(unknown) > 0 {
+			return result, fmt.Errorf("%w: %s", ErrUnknownStatusClass, strings.Join(unknown, ", "))
 		}
-	})
+	}
 
-	if gotCount != wantServiceCount {
-		return result, fmt.Errorf("the number of status icons on the page does not match the number of statuses in the database. something has changed")
+	if err = result.Validate(); err != nil {
+		return result, err
 	}
 
 	slices.SortStableFunc(result, func(a, b ParsedStatus) int {
@@ -541,6 +1953,100 @@ func parsePageStatuses(doc *goquery.Document, services []*Service, statuses []*S
 	return result, nil
 }
 
+/*
+matchStatus returns the known status whose class name the icon carries.
+An icon with no known class gets an "Unknown" status instead, an error
+only if Config.UnknownStatusIsError is set, so a new icon on the status
+page never leaves a service without a status.
+*/
+func matchStatus(icon *goquery.Selection, statuses []*Status) *Status {
+	for _, status := range statuses {
+		if icon.HasClass(status.ClassName) {
+			return status
+		}
+	}
+
+	className, _ := icon.Attr("class")
+	slog.Warn("status icon has no known class. using the unknown status", "class", className)
+
+	return &Status{
+		Status:    "Unknown",
+		ClassName: className,
+		IsError:   config.UnknownStatusIsError,
+	}
+}
+
+/*
+logMatchedSelections logs, at debug level, every element matched by the
+service name and status icon selectors before they are paired. Comparing
+the two lists shows where a count mismatch comes from.
+*/
+func logMatchedSelections(doc *goquery.Document) {
+	serviceNames := doc.Find(serviceNameSelector)
+	slog.Debug("service name selector matches", "selector", serviceNameSelector, "count", serviceNames.Length())
+
+	serviceNames.Each(func(i int, s *goquery.Selection) {
+		slog.Debug("service name match", "index", i, "text", strings.TrimSpace(s.Text()))
+	})
+
+	icons := doc.Find(statusIconSelector)
+	slog.Debug("status icon selector matches", "selector", statusIconSelector, "count", icons.Length())
+
+	icons.Each(func(i int, s *goquery.Selection) {
+		className, _ := s.Attr("class")
+		slog.Debug("status icon match", "index", i, "class", className)
+	})
+}
+
+/*
+parseIncidentLink returns the absolute URL of the first incident link on
+the status page matched by Config.IncidentLinkSelector, or an empty string
+when there is none.
+*/
+func parseIncidentLink(doc *goquery.Document, pageURL string) string {
+	var (
+		err  error
+		base *url.URL
+		ref  *url.URL
+	)
+
+	href, ok := doc.Find(config.IncidentLinkSelector).First().Attr("href")
+
+	if !ok || strings.TrimSpace(href) == "" {
+		return ""
+	}
+
+	if base, err = url.Parse(pageURL); err != nil {
+		return ""
+	}
+
+	if ref, err = url.Parse(strings.TrimSpace(href)); err != nil {
+		slog.Debug("ignoring unparseable incident link", "href", href, "error", err)
+		return ""
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+/*
+parseGroupName returns the name of the section a service belongs to, taken
+from the first heading inside the closest Config.GroupSelector ancestor.
+An empty string is returned when grouping is not configured or found.
+*/
+func parseGroupName(s *goquery.Selection) string {
+	if config.GroupSelector == "" {
+		return ""
+	}
+
+	group := s.Closest(config.GroupSelector)
+
+	if group.Length() == 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(group.Find("h1, h2, h3, h4").First().Text())
+}
+
 func postToAnalytics(r *http.Request) error {
 	var (
 		err    error
@@ -554,7 +2060,7 @@ func postToAnalytics(r *http.Request) error {
 
 	payload := AleticsPayload{
 		Token:       config.AleticsToken,
-		Path:        "/status.rss",
+		Path:        r.URL.Path,
 		QueryString: "",
 		Browser:     getBrowser(r.UserAgent()),
 	}
@@ -598,24 +2104,67 @@ func getBrowser(ua string) string {
 Data functions
 *******************************************************
 */
+/*
+queryFeed returns the newest feed items from the read database with
+their descriptions decompressed.
+*/
 func queryFeed(limit int) ([]*Feed, error) {
+	return queryFeedFrom(readDB, limit)
+}
+
+/*
+queryFeedFrom is queryFeed against a specific connection. Code that has
+just written to the feed reads from the primary, which a read replica
+may lag behind.
+*/
+func queryFeedFrom(conn *gorm.DB, limit int) ([]*Feed, error) {
+	var (
+		err  error
+		feed []*Feed
+	)
+
 	ctx, cancel := getContext()
 	defer cancel()
 
-	tx := gorm.G[*Feed](db).Order("created_at DESC")
+	tx := gorm.G[*Feed](conn).Order("created_at DESC")
 
 	if limit > 0 {
 		tx = tx.Limit(limit)
 	}
 
-	return tx.Find(ctx)
+	if feed, err = tx.Find(ctx); err != nil {
+		return feed, err
+	}
+
+	for _, f := range feed {
+		if f.Description, err = decompressDescription(f.Description); err != nil {
+			return feed, fmt.Errorf("error reading description of feed item %d: %w", f.ID, err)
+		}
+	}
+
+	return feed, nil
 }
 
+/*
+queryLastStatus returns the singleton LastStatus row, which always has
+an ID of 1.
+*/
 func queryLastStatus() (*LastStatus, error) {
 	ctx, cancel := getContext()
 	defer cancel()
 
-	return gorm.G[*LastStatus](db).First(ctx)
+	return gorm.G[*LastStatus](db).Where("id=1").First(ctx)
+}
+
+/*
+deleteStrayLastStatuses removes any LastStatus rows other than the
+singleton, such as ones added by hand.
+*/
+func deleteStrayLastStatuses() (int, error) {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	return gorm.G[LastStatus](db).Where("id <> 1").Delete(ctx)
 }
 
 func queryStatuses() ([]*Status, error) {
@@ -634,6 +2183,10 @@ func queryStatuses() ([]*Status, error) {
 	return statuses, nil
 }
 
+/*
+queryServices returns the active services. Disabled services keep their
+history but are no longer expected on the status page.
+*/
 func queryServices() ([]*Service, error) {
 	var (
 		err      error
@@ -643,41 +2196,212 @@ func queryServices() ([]*Service, error) {
 	ctx, cancel := getContext()
 	defer cancel()
 
-	if services, err = gorm.G[*Service](db).Find(ctx); err != nil {
+	if services, err = gorm.G[*Service](db).Where("active = ?", true).Find(ctx); err != nil {
 		return services, fmt.Errorf("error querying services: %w", err)
 	}
 
 	return services, nil
 }
 
-func insertLastStatus(hash string) error {
+/*
+insertLastStatus writes the singleton LastStatus row. Should another
+replica have created it first, the existing row is overwritten rather than
+a second row being added.
+*/
+func insertLastStatus(hash string, validators PageValidators, states ParsedStatusCollection) error {
+	var (
+		outageStartedAt time.Time
+	)
+
 	ctx, cancel := getContext()
 	defer cancel()
 
-	return gorm.G[LastStatus](db).Create(ctx, &LastStatus{
-		ID:             1,
-		UpdatedAt:      time.Now(),
-		LastStatusHash: hash,
+	if states.HasErrors() {
+		outageStartedAt = time.Now()
+	}
+
+	return gorm.G[LastStatus](db, clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		UpdateAll: true,
+	}).Create(ctx, &LastStatus{
+		ID:              1,
+		UpdatedAt:       time.Now(),
+		ChangedAt:       time.Now(),
+		LastSuccessAt:   time.Now(),
+		LastStatusHash:  hash,
+		ETag:            validators.ETag,
+		LastModified:    validators.LastModified,
+		Snapshot:        states.Snapshot(),
+		OutageStartedAt: outageStartedAt,
+	})
+}
+
+func updateLastStatus(hash string, states ParsedStatusCollection, validators PageValidators) error {
+	return updateLastStatusSnapshot(hash, states.Snapshot(), validators)
+}
+
+/*
+updateLastStatusSnapshot records a status change. The page validators are
+written in the same update as the hash, so a failed update can't leave
+new validators behind that would turn the next check into a 304 and hide
+the change.
+*/
+func updateLastStatusSnapshot(hash, snapshot string, validators PageValidators) error {
+	return withWriteRetry("update last status", func() error {
+		ctx, cancel := getContext()
+		defer cancel()
+
+		_, err := gorm.G[LastStatus](db).
+			Where("id=1").
+			Select("last_status_hash", "changed_at", "snapshot", "etag", "last_modified").
+			Updates(ctx, LastStatus{
+				LastStatusHash: hash,
+				ChangedAt:      time.Now(),
+				Snapshot:       snapshot,
+				ETag:           validators.ETag,
+				LastModified:   validators.LastModified,
+			})
+
+		return err
 	})
 }
 
-func updateLastStatus(hash string) error {
+func updatePageValidators(validators PageValidators) error {
+	var (
+		err error
+	)
+
 	ctx, cancel := getContext()
 	defer cancel()
 
-	_, err := gorm.G[LastStatus](db).Where("id=1").Update(ctx, "last_status_hash", hash)
+	if _, err = gorm.G[LastStatus](db).Where("id=1").Update(ctx, "etag", validators.ETag); err != nil {
+		return err
+	}
+
+	_, err = gorm.G[LastStatus](db).Where("id=1").Update(ctx, "last_modified", validators.LastModified)
 	return err
 }
 
-func insertRssItem(item RssItem) error {
-	ctx, cancel := getContext()
-	defer cancel()
+/*
+insertRssItem writes a feed item. When Config.DedupeWindow is set and an
+item with the same content was written within the window, that item is
+moved to the top of the feed with a fresh timestamp instead, so a
+flapping service doesn't fill the feed with repeats. The item's Metadata
+is appended to the stored description but left out of the content hash.
+*/
+func insertRssItem(item RssItem) (uint, error) {
+	var (
+		err      error
+		existing *Feed
+	)
 
 	feedItem := Feed{
 		Title:       item.Title,
 		PubDate:     item.PubDate,
-		Description: item.Description,
+		Description: item.Description + item.Metadata,
+		Severity:    item.Severity,
+		Link:        item.Link,
+		ContentHash: generateContentHash(item),
+		Snapshot:    item.Snapshot,
+	}
+
+	if config.DedupeWindow > 0 {
+		if existing, err = queryRecentFeedItemByContentHash(feedItem.ContentHash, time.Now().Add(-config.DedupeWindow)); err == nil {
+			slog.Info("feed item repeats a recent item. refreshing it instead", "feedID", existing.ID)
+
+			if err = refreshFeedItem(existing.ID, item.PubDate); err != nil {
+				return 0, err
+			}
+
+			if err = invalidateFeedCache(); err != nil {
+				slog.Error("error invalidating feed cache", "error", err)
+			}
+
+			if err = publishFeedToS3(); err != nil {
+				slog.Error("error publishing feed to S3", "error", err)
+			}
+
+			return existing.ID, nil
+		}
+
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Error("error querying recent feed items for duplicates", "error", err)
+		}
+	}
+
+	if config.CompressDescriptions {
+		if feedItem.Description, err = compressDescription(feedItem.Description); err != nil {
+			return 0, fmt.Errorf("error compressing RSS item description: %w", err)
+		}
+	}
+
+	err = withWriteRetry("insert RSS item", func() error {
+		ctx, cancel := getContext()
+		defer cancel()
+
+		return gorm.G[Feed](db).Create(ctx, &feedItem)
+	})
+
+	if err != nil {
+		holdFeedItem(item)
+		return 0, err
+	}
+
+	if err = invalidateFeedCache(); err != nil {
+		slog.Error("error invalidating feed cache", "error", err)
+	}
+
+	if err = publishFeedToS3(); err != nil {
+		slog.Error("error publishing feed to S3", "error", err)
+	}
+
+	dispatchNotifications(item)
+	return feedItem.ID, nil
+}
+
+func queryRecentFeedItemByContentHash(contentHash string, since time.Time) (*Feed, error) {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	return gorm.G[*Feed](db).
+		Where("content_hash=? AND created_at >= ?", contentHash, since).
+		Order("created_at DESC").
+		First(ctx)
+}
+
+func refreshFeedItem(id uint, pubDate time.Time) error {
+	return withWriteRetry("refresh RSS item", func() error {
+		ctx, cancel := getContext()
+		defer cancel()
+
+		_, err := gorm.G[Feed](db).Where("id=?", id).Updates(ctx, Feed{CreatedAt: time.Now(), PubDate: pubDate})
+		return err
+	})
+}
+
+func queryStatusTransitions(limit int) ([]*StatusTransition, error) {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	tx := gorm.G[*StatusTransition](readDB).Order("created_at DESC")
+
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+
+	return tx.Find(ctx)
+}
+
+func insertStatusTransition(previousHash, newHash string, states ParsedStatusCollection, feedID uint) error {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	transition := StatusTransition{
+		PreviousHash:     previousHash,
+		NewHash:          newHash,
+		AffectedServices: strings.Join(states.ErrorServiceNames(), ", "),
+		FeedID:           feedID,
 	}
 
-	return gorm.G[Feed](db).Create(ctx, &feedItem)
+	return gorm.G[StatusTransition](db).Create(ctx, &transition)
 }
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := map[string]int{
+		"/status.rss":                 10,
+		"/status.rss?limit=":          10,
+		"/status.rss?limit=5":         5,
+		"/status.rss?limit=100":       100,
+		"/status.rss?limit=500":       100,
+		"/status.rss?limit=0":         10,
+		"/status.rss?limit=-3":        10,
+		"/status.rss?limit=ten":       10,
+		"/status.rss?limit=2.5":       10,
+		"/status.rss?other=7":         10,
+		"/status.rss?limit=7&limit=9": 7,
+	}
+
+	for target, want := range tests {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+
+		if got := parseLimit(r, 10, 100); got != want {
+			t.Errorf("%s: expected %d, got %d", target, want, got)
+		}
+	}
+}
+
+func TestParsePositiveIntParam(t *testing.T) {
+	tests := map[string]int{
+		"/slo.json":          30,
+		"/slo.json?days=7":   7,
+		"/slo.json?days=365": 90,
+		"/slo.json?days=0":   30,
+		"/slo.json?days=x":   30,
+		"/slo.json?limit=7":  30,
+	}
+
+	for target, want := range tests {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+
+		if got := parsePositiveIntParam(r, "days", 30, 90); got != want {
+			t.Errorf("%s: expected %d, got %d", target, want, got)
+		}
+	}
+}
+
+func TestLimitPageBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		maxBytes int
+		wantErr  bool
+	}{
+		{name: "under limit", body: "hello", maxBytes: 10},
+		{name: "exactly at limit", body: "hello", maxBytes: 5},
+		{name: "over limit", body: "hello!", maxBytes: 5, wantErr: true},
+		{name: "limit disabled", body: strings.Repeat("x", 1000), maxBytes: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := limitPageBody(strings.NewReader(tt.body), tt.maxBytes)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrPageTooLarge) {
+					t.Fatalf("expected ErrPageTooLarge, got %v", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if b, _ := io.ReadAll(body); string(b) != tt.body {
+				t.Errorf("expected the whole body, got %q", b)
+			}
+		})
+	}
+}
+
+func TestGrabStatusPageRejectsOversizedPage(t *testing.T) {
+	setTestConfig(t, &Config{MaxPageBytes: 64})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testPageOperational))
+	}))
+	defer server.Close()
+
+	if _, _, err := grabStatusPage(server.URL, PageValidators{}); !errors.Is(err, ErrPageTooLarge) {
+		t.Errorf("expected ErrPageTooLarge, got %v", err)
+	}
+}
+
+func TestRunMigrations(t *testing.T) {
+	connectTestDB(t)
+
+	for range 2 {
+		if err := runMigrations(); err != nil {
+			t.Fatalf("expected migrations to succeed, got %v", err)
+		}
+	}
+
+	for _, model := range []any{&Service{}, &Status{}, &ServiceStatus{}, &Feed{}, &LastStatus{}, &CronLock{}, &StatusTransition{}, &FeedCache{}, &MutedService{}} {
+		if !db.Migrator().HasTable(model) {
+			t.Errorf("expected a table for %T", model)
+		}
+	}
+
+	sqlDB, err := db.DB()
+
+	if err != nil {
+		t.Fatalf("error getting database handle: %v", err)
+	}
+
+	sqlDB.Close()
+
+	if err = runMigrations(); err == nil {
+		t.Errorf("expected an error migrating a closed database")
+	}
+}
+
+func TestParsePageStatusesRequiresJS(t *testing.T) {
+	setTestConfig(t, &Config{})
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><body><div id="root"></div><script src="/app.js"></script></body></html>`))
+
+	if err != nil {
+		t.Fatalf("error parsing document: %v", err)
+	}
+
+	if _, err = parsePageStatuses(doc, []*Service{{ServiceName: "Checkout"}}, []*Status{{Status: "Operational", ClassName: "ok"}}); !errors.Is(err, ErrPageRequiresJS) {
+		t.Errorf("expected ErrPageRequiresJS, got %v", err)
+	}
+}
+
+func TestParsePageStatusesDuplicateService(t *testing.T) {
+	setTestConfig(t, &Config{})
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(testServicePage([2]string{"Checkout", "ok"}, [2]string{"Checkout", "down"})))
+
+	if err != nil {
+		t.Fatalf("error parsing document: %v", err)
+	}
+
+	services := []*Service{{ServiceName: "Checkout"}, {ServiceName: "Storefront"}}
+	statuses := []*Status{{Status: "Operational", ClassName: "ok"}, {Status: "Outage", ClassName: "down", IsError: true}}
+
+	if _, err = parsePageStatuses(doc, services, statuses); !errors.Is(err, ErrDuplicateService) || !strings.Contains(err.Error(), "Checkout") {
+		t.Errorf("expected ErrDuplicateService naming Checkout, got %v", err)
+	}
+}
+
+func TestGenerateStatusHashErrorOnly(t *testing.T) {
+	checkout := &Service{ServiceName: "Checkout"}
+	operational := &Status{Status: "Operational", ClassName: "ok"}
+	restyled := &Status{Status: "Operational", ClassName: "ok-v2"}
+	outage := &Status{Status: "Outage", ClassName: "down", IsError: true}
+
+	tests := []struct {
+		name     string
+		hashMode string
+		before   *Status
+		after    *Status
+		changed  bool
+	}{
+		{name: "full mode sees cosmetic class change", hashMode: HashModeFull, before: operational, after: restyled, changed: true},
+		{name: "error-only ignores cosmetic class change", hashMode: HashModeErrorOnly, before: operational, after: restyled, changed: false},
+		{name: "error-only sees an outage", hashMode: HashModeErrorOnly, before: operational, after: outage, changed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, &Config{HashMode: tt.hashMode})
+
+			before := generateStatusHash([]ParsedStatus{{Service: checkout, Status: tt.before}})
+			after := generateStatusHash([]ParsedStatus{{Service: checkout, Status: tt.after}})
+
+			if (before != after) != tt.changed {
+				t.Errorf("expected changed=%v, got hashes %s and %s", tt.changed, before, after)
+			}
+		})
+	}
+}
+
+func TestParsePageStatusesDebugSelections(t *testing.T) {
+	var (
+		logs bytes.Buffer
+	)
+
+	previousLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previousLogger) })
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(testServicePage([2]string{"Checkout", "ok"}, [2]string{"Storefront", "down"})))
+
+	if err != nil {
+		t.Fatalf("error parsing document: %v", err)
+	}
+
+	services := []*Service{{ServiceName: "Checkout"}, {ServiceName: "Storefront"}}
+	statuses := []*Status{{Status: "Operational", ClassName: "ok"}, {Status: "Outage", ClassName: "down", IsError: true}}
+
+	setTestConfig(t, &Config{})
+
+	if _, err = parsePageStatuses(doc, services, statuses); err != nil {
+		t.Fatalf("error parsing statuses: %v", err)
+	}
+
+	if strings.Contains(logs.String(), "service name match") {
+		t.Errorf("expected no selection logs with DebugSelections off, got %s", logs.String())
+	}
+
+	config.DebugSelections = true
+
+	if _, err = parsePageStatuses(doc, services, statuses); err != nil {
+		t.Fatalf("error parsing statuses: %v", err)
+	}
+
+	for _, want := range []string{
+		"service name selector matches",
+		"count=2",
+		"text=Storefront",
+		"status icon selector matches",
+		"class=down",
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expected %q in the debug logs, got %s", want, logs.String())
+		}
+	}
+}
+
+func TestParsePageStatusesUnknownStatusFallback(t *testing.T) {
+	tests := []struct {
+		name                 string
+		unknownStatusIsError bool
+	}{
+		{name: "operational by default", unknownStatusIsError: false},
+		{name: "error when configured", unknownStatusIsError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, &Config{UnknownStatusIsError: tt.unknownStatusIsError})
+
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(testServicePage([2]string{"Checkout", "ok"}, [2]string{"Storefront", "sparkly"})))
+
+			if err != nil {
+				t.Fatalf("error parsing document: %v", err)
+			}
+
+			services := []*Service{{ServiceName: "Checkout"}, {ServiceName: "Storefront"}}
+			statuses := []*Status{{Status: "Operational", ClassName: "ok"}}
+
+			states, err := parsePageStatuses(doc, services, statuses)
+
+			if err != nil {
+				t.Fatalf("error parsing statuses: %v", err)
+			}
+
+			unknown := states[1].Status
+
+			if unknown == nil || unknown.Status != "Unknown" || unknown.ClassName != "sparkly" || unknown.IsError != tt.unknownStatusIsError {
+				t.Fatalf("expected the unknown fallback status, got %+v", unknown)
+			}
+
+			if states.HasErrors() != tt.unknownStatusIsError {
+				t.Errorf("expected HasErrors %v, got %v", tt.unknownStatusIsError, states.HasErrors())
+			}
+
+			if hash := generateStatusHash(states); hash == "" {
+				t.Errorf("expected a status hash")
+			}
+		})
+	}
+}
+
+func TestCronJobWithUnknownStatusClass(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.UnknownStatusIsError = true
+	result := runTestCheck(t, testServicePage([2]string{"Checkout", "ok"}, [2]string{"Storefront", "sparkly"}))
+
+	if result.Err != nil || !result.HasErrors {
+		t.Fatalf("expected the unknown status to be treated as an error, got %+v", result)
+	}
+
+	feed := queryTestFeed(t)
+
+	if len(feed) != 1 || !strings.Contains(feed[0].Description, "Storefront - Unknown") {
+		t.Errorf("expected an error item naming the unknown status, got %+v", feed)
+	}
+}
+
+func TestCheckRedirectHost(t *testing.T) {
+	tests := []struct {
+		name                 string
+		allowedRedirectHosts string
+		requested            string
+		final                string
+		wantErr              bool
+	}{
+		{name: "same host", requested: "https://www.shopifystatus.com/", final: "https://www.shopifystatus.com/status", wantErr: false},
+		{name: "host differs only in case", requested: "https://www.shopifystatus.com/", final: "https://WWW.ShopifyStatus.com/", wantErr: false},
+		{name: "unexpected host", requested: "https://www.shopifystatus.com/", final: "https://login.example.com/", wantErr: true},
+		{name: "allowed host", allowedRedirectHosts: "cdn.example.com, login.example.com", requested: "https://www.shopifystatus.com/", final: "https://login.example.com/", wantErr: false},
+		{name: "port is ignored", requested: "https://www.shopifystatus.com/", final: "https://www.shopifystatus.com:8443/", wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, &Config{AllowedRedirectHosts: tt.allowedRedirectHosts})
+
+			requested, _ := url.Parse(tt.requested)
+			final, _ := url.Parse(tt.final)
+
+			err := checkRedirectHost(requested, final)
+
+			if tt.wantErr != errors.Is(err, ErrUnexpectedRedirect) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGrabStatusPageRejectsRedirectToAnotherHost(t *testing.T) {
+	setTestConfig(t, &Config{})
+
+	portal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>Please log in</body></html>`))
+	}))
+	defer portal.Close()
+
+	portalURL, _ := url.Parse(portal.URL)
+	portalURL.Host = "localhost:" + portalURL.Port()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, portalURL.String(), http.StatusFound)
+	}))
+	defer server.Close()
+
+	if _, _, err := grabStatusPage(server.URL, PageValidators{}); !errors.Is(err, ErrUnexpectedRedirect) {
+		t.Errorf("expected ErrUnexpectedRedirect, got %v", err)
+	}
+
+	config.AllowedRedirectHosts = "localhost"
+
+	if _, _, err := grabStatusPage(server.URL, PageValidators{}); err != nil {
+		t.Errorf("expected an allowed redirect to be followed, got %v", err)
+	}
+}
+
+func TestGrabStatusPageRequiresRootContainer(t *testing.T) {
+	setTestConfig(t, &Config{RootContainerSelector: "div.flex-col"})
+
+	page := testPageOperational
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	if _, _, err := grabStatusPage(server.URL, PageValidators{}); err != nil {
+		t.Errorf("expected a page with the root container to be accepted, got %v", err)
+	}
+
+	page = `<html><body><h1>We'll be right back</h1></body></html>`
+
+	if _, _, err := grabStatusPage(server.URL, PageValidators{}); !errors.Is(err, ErrPageEmpty) {
+		t.Errorf("expected ErrPageEmpty, got %v", err)
+	}
+
+	config.RootContainerSelector = ""
+
+	if _, _, err := grabStatusPage(server.URL, PageValidators{}); err != nil {
+		t.Errorf("expected the check to be skipped without a selector, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/*
+maintenanceJob runs housekeeping on Config.MaintenanceSchedule, separate
+from the status checks so heavier deletes don't delay them.
+*/
+func maintenanceJob() {
+	var (
+		err     error
+		removed int
+		pruned  int
+	)
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			slog.Error("maintenance panicked", "panic", recovered, "stack", string(debug.Stack()))
+		}
+	}()
+
+	if config.FeedRetention > 0 {
+		if removed, err = pruneFeed(time.Now().Add(-config.FeedRetention)); err != nil {
+			slog.Error("error pruning feed items", "error", err)
+		} else if removed > 0 {
+			slog.Info("pruned old feed items", "count", removed)
+		}
+
+		pruned += removed
+
+		if removed, err = pruneStatusTransitions(time.Now().Add(-config.FeedRetention)); err != nil {
+			slog.Error("error pruning status transitions", "error", err)
+		} else if removed > 0 {
+			slog.Info("pruned old status transitions", "count", removed)
+		}
+
+		pruned += removed
+
+		if removed, err = pruneServiceStatusHistory(time.Now().Add(-config.FeedRetention)); err != nil {
+			slog.Error("error pruning service status history", "error", err)
+		} else if removed > 0 {
+			slog.Info("pruned old service status history", "count", removed)
+		}
+
+		pruned += removed
+	}
+
+	if config.VacuumAfterPrune && pruned > 0 {
+		vacuumSqlite()
+	}
+
+	if config.StaleLockAge > 0 {
+		if removed, err = deleteStaleCronLocks(time.Now().Add(-config.StaleLockAge)); err != nil {
+			slog.Error("error removing stale cron locks", "error", err)
+		} else if removed > 0 {
+			slog.Warn("removed stale cron locks", "count", removed)
+		}
+	}
+}
+
+/*
+minVacuumInterval keeps VACUUM, which rewrites the whole database file,
+from running more than once a day however often maintenance is scheduled.
+*/
+const minVacuumInterval = 24 * time.Hour
+
+var lastVacuumAt time.Time
+
+/*
+vacuumSqlite reclaims the space left by pruning, which SQLite does not
+return to the file system on its own. It does nothing on other databases
+or when it last ran within minVacuumInterval.
+*/
+func vacuumSqlite() {
+	var (
+		err error
+	)
+
+	if db.Dialector.Name() != "sqlite" {
+		return
+	}
+
+	if !lastVacuumAt.IsZero() && time.Since(lastVacuumAt) < minVacuumInterval {
+		slog.Info("skipping vacuum. it ran recently", "lastVacuumAt", lastVacuumAt)
+		return
+	}
+
+	/*
+	 * VACUUM can take longer than getContext allows on a large database.
+	 */
+	ctx := context.Background()
+	start := time.Now()
+
+	if err = gorm.G[any](db).Exec(ctx, "VACUUM"); err != nil {
+		slog.Error("error vacuuming database", "error", err)
+		return
+	}
+
+	if err = gorm.G[any](db).Exec(ctx, "ANALYZE"); err != nil {
+		slog.Error("error analyzing database", "error", err)
+	}
+
+	lastVacuumAt = time.Now()
+	slog.Info("vacuumed database", "duration", time.Since(start))
+}
+
+/*
+pruneFeed permanently deletes feed items created before cutoff and
+invalidates cached feeds if anything was removed.
+*/
+func pruneFeed(cutoff time.Time) (int, error) {
+	var (
+		err     error
+		removed int
+	)
+
+	err = withWriteRetry("prune feed", func() error {
+		ctx, cancel := getContext()
+		defer cancel()
+
+		removed, err = gorm.G[Feed](db).
+			Scopes(func(db *gorm.Statement) {
+				db.Unscoped = true
+			}).
+			Where("created_at < ?", cutoff).
+			Delete(ctx)
+
+		return err
+	})
+
+	if err != nil || removed == 0 {
+		return removed, err
+	}
+
+	if err = invalidateFeedCache(); err != nil {
+		slog.Error("error invalidating feed cache", "error", err)
+	}
+
+	return removed, nil
+}
+
+func pruneStatusTransitions(cutoff time.Time) (int, error) {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	return gorm.G[StatusTransition](db).
+		Scopes(func(db *gorm.Statement) {
+			db.Unscoped = true
+		}).
+		Where("created_at < ?", cutoff).
+		Delete(ctx)
+}
+
+/*
+deleteStaleCronLocks removes cron locks taken before cutoff. A replica
+that dies mid-run never releases its lock, which would otherwise block
+that job everywhere.
+*/
+func deleteStaleCronLocks(cutoff time.Time) (int, error) {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	return gorm.G[CronLock](db).
+		Scopes(func(db *gorm.Statement) {
+			db.Unscoped = true
+		}).
+		Where("created_at < ?", cutoff).
+		Delete(ctx)
+}
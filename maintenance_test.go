@@ -0,0 +1,159 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestMaintenanceJobPrunesOldRows(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	runTestCheck(t, testPageOperational)
+
+	old := time.Now().Add(-48 * time.Hour)
+
+	for _, table := range []string{"feeds", "status_transitions", "service_statuses"} {
+		if err := gorm.G[any](db).Exec(ctx, "UPDATE "+table+" SET created_at = ?", old); err != nil {
+			t.Fatalf("error backdating %s: %v", table, err)
+		}
+	}
+
+	if err := gorm.G[CronLock](db).Create(ctx, &CronLock{Model: gorm.Model{CreatedAt: old}, Key: "stale"}); err != nil {
+		t.Fatalf("error creating stale lock: %v", err)
+	}
+
+	if err := gorm.G[CronLock](db).Create(ctx, &CronLock{Key: "held"}); err != nil {
+		t.Fatalf("error creating held lock: %v", err)
+	}
+
+	runTestCheck(t, testPageOutage)
+
+	config.FeedRetention = 24 * time.Hour
+	config.StaleLockAge = time.Hour
+	maintenanceJob()
+
+	if feed := queryTestFeed(t); len(feed) != 1 || feed[0].Title != "1 services reporting potential issues" {
+		t.Errorf("expected only the recent item to remain, got %+v", feed)
+	}
+
+	counts := map[string]func() (int64, error){
+		"status transitions": func() (int64, error) {
+			return gorm.G[StatusTransition](db).Scopes(unscoped).Count(ctx, "*")
+		},
+		"service status history": func() (int64, error) {
+			return gorm.G[ServiceStatus](db).Scopes(unscoped).Count(ctx, "*")
+		},
+		"feed items": func() (int64, error) {
+			return gorm.G[Feed](db).Scopes(unscoped).Count(ctx, "*")
+		},
+	}
+
+	want := map[string]int64{"status transitions": 1, "service status history": 2, "feed items": 1}
+
+	for name, count := range counts {
+		got, err := count()
+
+		if err != nil {
+			t.Fatalf("error counting %s: %v", name, err)
+		}
+
+		if got != want[name] {
+			t.Errorf("expected %d %s to remain, got %d", want[name], name, got)
+		}
+	}
+
+	locks, err := gorm.G[CronLock](db).Find(ctx)
+
+	if err != nil {
+		t.Fatalf("error querying cron locks: %v", err)
+	}
+
+	if len(locks) != 1 || locks[0].Key != "held" {
+		t.Errorf("expected only the held lock to remain, got %+v", locks)
+	}
+}
+
+func TestMaintenanceJobKeepsRowsWithoutRetention(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	runTestCheck(t, testPageOperational)
+
+	if err := gorm.G[any](db).Exec(ctx, "UPDATE feeds SET created_at = ?", time.Now().AddDate(-1, 0, 0)); err != nil {
+		t.Fatalf("error backdating feed: %v", err)
+	}
+
+	maintenanceJob()
+
+	if feed := queryTestFeed(t); len(feed) != 1 {
+		t.Errorf("expected nothing pruned without a retention, got %d items", len(feed))
+	}
+}
+
+func unscoped(statement *gorm.Statement) {
+	statement.Unscoped = true
+}
+
+func TestMaintenanceJobVacuumsAfterPruning(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	previousVacuumAt := lastVacuumAt
+	t.Cleanup(func() { lastVacuumAt = previousVacuumAt })
+	lastVacuumAt = time.Time{}
+
+	config.FeedRetention = 24 * time.Hour
+	config.VacuumAfterPrune = true
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	/*
+	 * Nothing to prune, so nothing to vacuum.
+	 */
+	runTestCheck(t, testPageOperational)
+	maintenanceJob()
+
+	if !lastVacuumAt.IsZero() {
+		t.Fatalf("expected no vacuum when nothing was pruned")
+	}
+
+	if err := gorm.G[any](db).Exec(ctx, "UPDATE feeds SET created_at = ?", time.Now().Add(-48*time.Hour)); err != nil {
+		t.Fatalf("error backdating feed: %v", err)
+	}
+
+	maintenanceJob()
+
+	if lastVacuumAt.IsZero() {
+		t.Fatalf("expected a vacuum after pruning")
+	}
+
+	/*
+	 * A second prune within minVacuumInterval doesn't vacuum again.
+	 */
+	vacuumedAt := lastVacuumAt
+	runTestCheck(t, testPageOutage)
+
+	if err := gorm.G[any](db).Exec(ctx, "UPDATE feeds SET created_at = ?", time.Now().Add(-48*time.Hour)); err != nil {
+		t.Fatalf("error backdating feed: %v", err)
+	}
+
+	maintenanceJob()
+
+	if !lastVacuumAt.Equal(vacuumedAt) {
+		t.Errorf("expected the vacuum to be skipped within %s", minVacuumInterval)
+	}
+
+	if feed := queryTestFeed(t); len(feed) != 0 {
+		t.Errorf("expected the second prune to run, got %d items", len(feed))
+	}
+}
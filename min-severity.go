@@ -0,0 +1,41 @@
+package main
+
+/*
+severityRanks orders the outage severities for Config.MinFeedSeverity.
+Severities not listed, such as info and recovery, rank below all of them.
+*/
+var severityRanks = map[string]int{
+	SeverityMinor: 1,
+	SeverityMajor: 2,
+}
+
+/*
+meetsMinFeedSeverity reports whether a change from previous to states is
+severe enough to write to the feed. The worse of the two sides counts, so
+an outage is written when it reaches the minimum and so is the recovery
+from it, while a recovery from a suppressed outage is not.
+*/
+func meetsMinFeedSeverity(previous []ServiceState, states ParsedStatusCollection) bool {
+	if config.MinFeedSeverity == "" {
+		return true
+	}
+
+	worst := max(severityRanks[states.Severity()], severityRanks[snapshotSeverity(previous)])
+	return worst >= severityRanks[config.MinFeedSeverity]
+}
+
+/*
+snapshotSeverity classifies stored service states the same way
+ParsedStatusCollection.Severity classifies parsed ones.
+*/
+func snapshotSeverity(states []ServiceState) string {
+	errorCount := 0
+
+	for _, state := range states {
+		if state.IsError {
+			errorCount++
+		}
+	}
+
+	return severityForCounts(errorCount, len(states))
+}
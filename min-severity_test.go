@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMeetsMinFeedSeverity(t *testing.T) {
+	operational := &Status{Status: "Operational", ClassName: "ok"}
+	outage := &Status{Status: "Outage", ClassName: "down", IsError: true}
+
+	/*
+	 * states returns four services with the first errorCount in error.
+	 */
+	states := func(errorCount int) ParsedStatusCollection {
+		result := ParsedStatusCollection{}
+
+		for i := range 4 {
+			status := operational
+
+			if i < errorCount {
+				status = outage
+			}
+
+			result = append(result, ParsedStatus{Service: &Service{ServiceName: fmt.Sprintf("Service %d", i)}, Status: status})
+		}
+
+		return result
+	}
+
+	tests := []struct {
+		name            string
+		minFeedSeverity string
+		previousErrors  int
+		errors          int
+		want            bool
+	}{
+		{name: "no minimum writes a minor outage", minFeedSeverity: "", previousErrors: 0, errors: 1, want: true},
+		{name: "minor outage below major minimum", minFeedSeverity: SeverityMajor, previousErrors: 0, errors: 1, want: false},
+		{name: "major outage meets major minimum", minFeedSeverity: SeverityMajor, previousErrors: 0, errors: 2, want: true},
+		{name: "minor outage growing to major", minFeedSeverity: SeverityMajor, previousErrors: 1, errors: 3, want: true},
+		{name: "recovery from major outage", minFeedSeverity: SeverityMajor, previousErrors: 2, errors: 0, want: true},
+		{name: "recovery from suppressed outage", minFeedSeverity: SeverityMajor, previousErrors: 1, errors: 0, want: false},
+		{name: "minor outage meets minor minimum", minFeedSeverity: SeverityMinor, previousErrors: 0, errors: 1, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, &Config{MajorSeverityFraction: 0.5, MinFeedSeverity: tt.minFeedSeverity})
+
+			previous := parseSnapshot(states(tt.previousErrors).Snapshot())
+
+			if got := meetsMinFeedSeverity(previous, states(tt.errors)); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCronJobSkipsChangesBelowMinFeedSeverity(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.MajorSeverityFraction = 0.75
+	config.MinFeedSeverity = SeverityMajor
+	bothDown := testServicePage([2]string{"Checkout", "down"}, [2]string{"Storefront", "down"})
+
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, testPageOutage)
+	runTestCheck(t, testPageOperational)
+
+	if feed := queryTestFeed(t); len(feed) != 1 {
+		t.Fatalf("expected a minor outage and its recovery to be skipped, got %d items", len(feed))
+	}
+
+	runTestCheck(t, bothDown)
+
+	if feed := queryTestFeed(t); len(feed) != 2 || feed[0].Severity != SeverityMajor {
+		t.Errorf("expected the major outage to be written, got %+v", feed)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log/slog"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"gorm.io/gorm"
+)
+
+/*
+reconcileMissingServices tracks services that no longer appear on the
+status page, which otherwise fail every check with a count mismatch. Once
+a service has been missing for Config.MissingServiceRuns checks in a row,
+it is either disabled, when Config.AutoDisableMissingServices is set, or a
+warning item is written. Disabled services are left out of the returned
+slice so the current check can proceed. A MissingServiceRuns of zero
+turns tracking off.
+*/
+func reconcileMissingServices(doc *goquery.Document, services []*Service) []*Service {
+	var (
+		err error
+	)
+
+	if config.MissingServiceRuns <= 0 {
+		return services
+	}
+
+	found := map[string]bool{}
+
+	doc.Find(serviceNameSelector).Each(func(i int, s *goquery.Selection) {
+		found[s.Text()] = true
+	})
+
+	/*
+	 * An empty page is reported by parsePageStatuses. It says nothing
+	 * about individual services.
+	 */
+	if len(found) == 0 {
+		return services
+	}
+
+	result := make([]*Service, 0, len(services))
+
+	for _, service := range services {
+		if found[service.ServiceName] {
+			if service.MissingRuns > 0 {
+				service.MissingRuns = 0
+
+				if err = updateServiceMissingRuns(service.ID, 0); err != nil {
+					slog.Error("error resetting missing runs", "service", service.ServiceName, "error", err)
+				}
+			}
+
+			result = append(result, service)
+			continue
+		}
+
+		service.MissingRuns++
+		slog.Warn("service is missing from the status page", "service", service.ServiceName, "missingRuns", service.MissingRuns, "threshold", config.MissingServiceRuns)
+
+		if err = updateServiceMissingRuns(service.ID, service.MissingRuns); err != nil {
+			slog.Error("error recording missing runs", "service", service.ServiceName, "error", err)
+		}
+
+		if service.MissingRuns < config.MissingServiceRuns {
+			result = append(result, service)
+			continue
+		}
+
+		if !config.AutoDisableMissingServices {
+			if service.MissingRuns == config.MissingServiceRuns {
+				if _, err = insertRssItem(generateMissingServiceFeedItem(service, false)); err != nil {
+					slog.Error("error inserting missing service RSS item", "service", service.ServiceName, "error", err)
+				}
+			}
+
+			result = append(result, service)
+			continue
+		}
+
+		if err = updateServiceActive(service.ID, false); err != nil {
+			slog.Error("error disabling missing service", "service", service.ServiceName, "error", err)
+			result = append(result, service)
+			continue
+		}
+
+		slog.Warn("disabled service missing from the status page", "service", service.ServiceName)
+
+		if _, err = insertRssItem(generateMissingServiceFeedItem(service, true)); err != nil {
+			slog.Error("error inserting missing service RSS item", "service", service.ServiceName, "error", err)
+		}
+	}
+
+	return result
+}
+
+func generateMissingServiceFeedItem(service *Service, disabled bool) RssItem {
+	action := "Status monitoring will fail until the service is removed or disabled."
+
+	if disabled {
+		action = "It has been disabled and is no longer monitored."
+	}
+
+	return RssItem{
+		Title: fmt.Sprintf("%s is no longer listed on the status page", service.ServiceName),
+		Link:  config.StatusPageURL,
+		Description: fmt.Sprintf(`<h2>Service No Longer Listed</h2><p>%s has not appeared on the Shopify status page
+			for %d checks. %s</p>`, html.EscapeString(service.ServiceName), service.MissingRuns, action),
+		PubDate:  time.Now().UTC(),
+		Severity: SeverityInfo,
+	}
+}
+
+func updateServiceMissingRuns(id uint, missingRuns int) error {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	_, err := gorm.G[Service](db).Where("id=?", id).Update(ctx, "missing_runs", missingRuns)
+	return err
+}
+
+func updateServiceActive(id uint, active bool) error {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	_, err := gorm.G[Service](db).Where("id=?", id).Update(ctx, "active", active)
+	return err
+}
+
+func updateServiceActiveByName(serviceName string, active bool) (int, error) {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	return gorm.G[Service](db).
+		Where("service_name=?", serviceName).
+		Select("active", "missing_runs").
+		Updates(ctx, Service{Active: active, MissingRuns: 0})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestMissingServiceWarning(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.MissingServiceRuns = 2
+	withoutStorefront := testServicePage([2]string{"Checkout", "ok"})
+
+	runTestCheck(t, testPageOperational)
+
+	for range 3 {
+		runTestCheck(t, withoutStorefront)
+	}
+
+	warnings := 0
+
+	for _, item := range queryTestFeed(t) {
+		if item.Title == "Storefront is no longer listed on the status page" {
+			warnings++
+		}
+	}
+
+	if warnings != 1 {
+		t.Errorf("expected one missing service warning, got %d", warnings)
+	}
+
+	storefront := queryTestService(t, "Storefront")
+
+	if !storefront.Active || storefront.MissingRuns != 3 {
+		t.Errorf("expected Storefront to stay active with 3 missing runs, got %+v", storefront)
+	}
+
+	/*
+	 * Reappearing resets the count.
+	 */
+	runTestCheck(t, testPageOperational)
+
+	if storefront = queryTestService(t, "Storefront"); storefront.MissingRuns != 0 {
+		t.Errorf("expected missing runs to reset, got %d", storefront.MissingRuns)
+	}
+}
+
+func TestMissingServiceAutoDisable(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.MissingServiceRuns = 2
+	config.AutoDisableMissingServices = true
+	withoutStorefront := testServicePage([2]string{"Checkout", "ok"})
+
+	runTestCheck(t, testPageOperational)
+
+	if result := runTestCheck(t, withoutStorefront); result.Err == nil {
+		t.Errorf("expected the first missing run to fail the count check")
+	}
+
+	if result := runTestCheck(t, withoutStorefront); result.Err != nil {
+		t.Errorf("expected the check to proceed once Storefront is disabled, got %v", result.Err)
+	}
+
+	if storefront := queryTestService(t, "Storefront"); storefront.Active {
+		t.Errorf("expected Storefront to be disabled")
+	}
+
+	announced := false
+
+	for _, item := range queryTestFeed(t) {
+		if item.Title == "Storefront is no longer listed on the status page" && strings.Contains(item.Description, "It has been disabled") {
+			announced = true
+		}
+	}
+
+	if !announced {
+		t.Errorf("expected an item announcing the disabled service")
+	}
+}
+
+func queryTestService(t *testing.T, serviceName string) Service {
+	t.Helper()
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	service, err := gorm.G[Service](db).Where("service_name=?", serviceName).First(ctx)
+
+	if err != nil {
+		t.Fatalf("error querying service %s: %v", serviceName, err)
+	}
+
+	return service
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/adampresley/httphelpers/requests"
+	"github.com/adampresley/httphelpers/responses"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+MutedService silences a noisy service until MutedUntil. A muted service
+is still checked and recorded in the status history, but its errors do
+not produce feed items.
+*/
+type MutedService struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ServiceName string    `gorm:"uniqueIndex" json:"serviceName"`
+	MutedUntil  time.Time `json:"mutedUntil"`
+}
+
+type MuteServiceRequest struct {
+	ServiceName string `json:"serviceName"`
+	Duration    string `json:"duration"`
+}
+
+/*
+applyServiceMutes returns the collection with currently muted services
+marked as muted. A muted service keeps its real status, so muting or
+unmuting it leaves the status hash alone, but it never counts as an
+error. hasUnmutedChanges keeps a muted service flapping out of the feed,
+while one still in error when its mute expires is reported.
+*/
+func applyServiceMutes(states ParsedStatusCollection) ParsedStatusCollection {
+	var (
+		err   error
+		muted []*MutedService
+	)
+
+	if muted, err = queryActiveMutes(); err != nil {
+		slog.Error("error querying muted services. mutes are not applied", "error", err)
+		return states
+	}
+
+	if len(muted) == 0 {
+		return states
+	}
+
+	result := make(ParsedStatusCollection, 0, len(states))
+
+	for _, state := range states {
+		if state.IsComplete() && isServiceMuted(muted, state.Service.ServiceName) {
+			slog.Debug("service is muted. ignoring its status", "service", state.Service.ServiceName, "status", state.Status.Status)
+			state.Muted = true
+		}
+
+		result = append(result, state)
+	}
+
+	return result
+}
+
+/*
+hasUnmutedChanges reports whether anything other than muted services
+differs from the previous snapshot. A service that was muted counts as
+changed only if it is now unmuted and in error.
+*/
+func hasUnmutedChanges(previous []ServiceState, states ParsedStatusCollection) bool {
+	for _, state := range states {
+		if !state.IsComplete() || state.Muted {
+			continue
+		}
+
+		before, found := findServiceState(previous, state.Service.ServiceName)
+
+		if !found {
+			return true
+		}
+
+		if before.Muted {
+			if state.IsError() {
+				return true
+			}
+
+			continue
+		}
+
+		if before.ClassName != state.Status.ClassName {
+			return true
+		}
+	}
+
+	for _, before := range previous {
+		if before.Muted {
+			continue
+		}
+
+		if !slices.ContainsFunc(states, func(state ParsedStatus) bool {
+			return state.IsComplete() && state.Service.ServiceName == before.ServiceName
+		}) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isServiceMuted(muted []*MutedService, serviceName string) bool {
+	for _, mute := range muted {
+		if mute.ServiceName == serviceName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func queryActiveMutes() ([]*MutedService, error) {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	return gorm.G[*MutedService](db).Where("muted_until > ?", time.Now()).Order("service_name").Find(ctx)
+}
+
+func upsertMutedService(serviceName string, mutedUntil time.Time) error {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	return gorm.G[MutedService](db, clause.OnConflict{
+		Columns:   []clause.Column{{Name: "service_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"muted_until"}),
+	}).Create(ctx, &MutedService{ServiceName: serviceName, MutedUntil: mutedUntil})
+}
+
+func deleteMutedService(serviceName string) (int, error) {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	return gorm.G[MutedService](db).Where("service_name=?", serviceName).Delete(ctx)
+}
+
+func adminListMutesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err   error
+			muted []*MutedService
+		)
+
+		if muted, err = queryActiveMutes(); err != nil {
+			slog.Error("error querying muted services", "error", err)
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, "An unexpected error occurred while querying muted services")
+			return
+		}
+
+		writeJsonOK(w, r, muted)
+	}
+}
+
+/*
+adminMuteHandler mutes a service for a duration such as "2h". Muting an
+already muted service replaces its expiry.
+*/
+func adminMuteHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err      error
+			body     MuteServiceRequest
+			duration time.Duration
+			services []*Service
+		)
+
+		if body, err = requests.Body[MuteServiceRequest](r); err != nil {
+			responses.JsonErrorMessage(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		if duration, err = time.ParseDuration(body.Duration); err != nil || duration <= 0 {
+			responses.JsonErrorMessage(w, http.StatusBadRequest, "duration must be a positive duration such as 2h")
+			return
+		}
+
+		if services, err = queryServices(); err != nil {
+			slog.Error("error querying services", "error", err)
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, "An unexpected error occurred while querying services")
+			return
+		}
+
+		if !isKnownService(services, body.ServiceName) {
+			responses.JsonErrorMessage(w, http.StatusNotFound, "unknown service")
+			return
+		}
+
+		mute := MutedService{ServiceName: body.ServiceName, MutedUntil: time.Now().Add(duration).UTC()}
+
+		if err = upsertMutedService(mute.ServiceName, mute.MutedUntil); err != nil {
+			slog.Error("error muting service", "error", err, "service", mute.ServiceName)
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, "An unexpected error occurred while muting the service")
+			return
+		}
+
+		slog.Info("service muted", "service", mute.ServiceName, "until", mute.MutedUntil)
+		writeJsonOK(w, r, mute)
+	}
+}
+
+func adminUnmuteHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err     error
+			removed int
+		)
+
+		serviceName := r.PathValue("serviceName")
+
+		if removed, err = deleteMutedService(serviceName); err != nil {
+			slog.Error("error unmuting service", "error", err, "service", serviceName)
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, "An unexpected error occurred while unmuting the service")
+			return
+		}
+
+		if removed == 0 {
+			responses.JsonErrorMessage(w, http.StatusNotFound, "service is not muted")
+			return
+		}
+
+		slog.Info("service unmuted", "service", serviceName)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func isKnownService(services []*Service, serviceName string) bool {
+	for _, service := range services {
+		if service.ServiceName == serviceName {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHasUnmutedChanges(t *testing.T) {
+	checkout := &Service{ServiceName: "Checkout"}
+	storefront := &Service{ServiceName: "Storefront"}
+	operational := &Status{Status: "Operational", ClassName: "ok"}
+	outage := &Status{Status: "Outage", ClassName: "down", IsError: true}
+
+	tests := []struct {
+		name     string
+		previous []ServiceState
+		states   ParsedStatusCollection
+		want     bool
+	}{
+		{
+			name:     "no change",
+			previous: []ServiceState{{ServiceName: "Checkout", ClassName: "ok"}, {ServiceName: "Storefront", ClassName: "ok"}},
+			states:   ParsedStatusCollection{{Service: checkout, Status: operational}, {Service: storefront, Status: operational}},
+			want:     false,
+		},
+		{
+			name:     "unmuted service changed",
+			previous: []ServiceState{{ServiceName: "Checkout", ClassName: "ok"}, {ServiceName: "Storefront", ClassName: "ok"}},
+			states:   ParsedStatusCollection{{Service: checkout, Status: operational}, {Service: storefront, Status: outage}},
+			want:     true,
+		},
+		{
+			name:     "muted service changed",
+			previous: []ServiceState{{ServiceName: "Checkout", ClassName: "ok"}, {ServiceName: "Storefront", ClassName: "ok"}},
+			states:   ParsedStatusCollection{{Service: checkout, Status: operational}, {Service: storefront, Status: outage, Muted: true}},
+			want:     false,
+		},
+		{
+			name:     "mute expired on a service in error",
+			previous: []ServiceState{{ServiceName: "Checkout", ClassName: "ok"}, {ServiceName: "Storefront", ClassName: "down", Muted: true}},
+			states:   ParsedStatusCollection{{Service: checkout, Status: operational}, {Service: storefront, Status: outage}},
+			want:     true,
+		},
+		{
+			name:     "mute expired on a recovered service",
+			previous: []ServiceState{{ServiceName: "Checkout", ClassName: "ok"}, {ServiceName: "Storefront", ClassName: "down", Muted: true}},
+			states:   ParsedStatusCollection{{Service: checkout, Status: operational}, {Service: storefront, Status: operational}},
+			want:     false,
+		},
+		{
+			name:     "unmuted service disappeared",
+			previous: []ServiceState{{ServiceName: "Checkout", ClassName: "ok"}, {ServiceName: "Storefront", ClassName: "ok"}},
+			states:   ParsedStatusCollection{{Service: checkout, Status: operational}},
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasUnmutedChanges(tt.previous, tt.states); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMutedServiceIsLeftOutOfTheFeed(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	runTestCheck(t, testPageOperational)
+
+	w := httptest.NewRecorder()
+	adminMuteHandler()(w, newMuteRequest(`{"serviceName":"Storefront","duration":"2h"}`))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 muting Storefront, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if result := runTestCheck(t, testPageOutage); result.HasErrors {
+		t.Errorf("expected a muted service not to count as an error")
+	}
+
+	if feed := queryTestFeed(t); len(feed) != 1 {
+		t.Fatalf("expected no item while Storefront is muted, got %d items", len(feed))
+	}
+
+	r := httptest.NewRequest(http.MethodDelete, "/admin/mutes/Storefront", nil)
+	r.SetPathValue("serviceName", "Storefront")
+
+	w = httptest.NewRecorder()
+	adminUnmuteHandler()(w, r)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 unmuting Storefront, got %d", w.Code)
+	}
+
+	runTestCheck(t, testPageOutage)
+
+	if feed := queryTestFeed(t); len(feed) != 2 || !strings.Contains(feed[0].Description, "Storefront - Outage") {
+		t.Errorf("expected the outage to be reported once unmuted, got %+v", feed)
+	}
+}
+
+func TestAdminMuteHandlerValidation(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "invalid body", body: `not json`, want: http.StatusBadRequest},
+		{name: "invalid duration", body: `{"serviceName":"Storefront","duration":"soon"}`, want: http.StatusBadRequest},
+		{name: "negative duration", body: `{"serviceName":"Storefront","duration":"-1h"}`, want: http.StatusBadRequest},
+		{name: "unknown service", body: `{"serviceName":"Payments","duration":"1h"}`, want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			adminMuteHandler()(w, newMuteRequest(tt.body))
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodDelete, "/admin/mutes/Checkout", nil)
+	r.SetPathValue("serviceName", "Checkout")
+
+	w := httptest.NewRecorder()
+	adminUnmuteHandler()(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 unmuting a service that isn't muted, got %d", w.Code)
+	}
+}
+
+func newMuteRequest(body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/admin/mutes", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	return r
+}
+
+func TestWriteServiceListItemMarksMutedServices(t *testing.T) {
+	tests := []struct {
+		name                 string
+		includeRawClassNames bool
+		muted                bool
+		want                 string
+	}{
+		{name: "live", want: "<li>Storefront - Outage</li>"},
+		{name: "muted", muted: true, want: "<li>Storefront - Outage (muted)</li>"},
+		{name: "live with class names", includeRawClassNames: true, want: "<li>Storefront - Outage<!-- className: down --></li>"},
+		{name: "muted with class names", includeRawClassNames: true, muted: true, want: "<li>Storefront - Outage (muted)<!-- className: down --></li>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, &Config{IncludeRawClassNames: tt.includeRawClassNames})
+
+			var description strings.Builder
+
+			writeServiceListItem(&description, ParsedStatus{
+				Service: &Service{ServiceName: "Storefront"},
+				Status:  &Status{Status: "Outage", ClassName: "down", IsError: true},
+				Muted:   tt.muted,
+			})
+
+			if description.String() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, description.String())
+			}
+		})
+	}
+}
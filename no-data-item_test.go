@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestNoDataItem(t *testing.T) {
+	setupTestDB(t)
+
+	feed, _ := getTestRss(t, "/status.rss")
+
+	if len(feed.Channel.Items) != 0 {
+		t.Errorf("expected an empty feed with the placeholder off, got %d items", len(feed.Channel.Items))
+	}
+
+	config.EmitNoDataItem = true
+	feed, _ = getTestRss(t, "/status.rss")
+
+	if len(feed.Channel.Items) != 1 || feed.Channel.Items[0].Title != "Monitoring starting, no data yet" {
+		t.Fatalf("expected the placeholder item, got %+v", feed.Channel.Items)
+	}
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if err := gorm.G[Feed](db).Create(ctx, &Feed{Title: "All services operational", PubDate: time.Now()}); err != nil {
+		t.Fatalf("error creating feed item: %v", err)
+	}
+
+	feed, _ = getTestRss(t, "/status.rss")
+
+	if len(feed.Channel.Items) != 1 || feed.Channel.Items[0].Title != "All services operational" {
+		t.Errorf("expected the placeholder to be replaced by the first item, got %+v", feed.Channel.Items)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+/*
+Notifier delivers a newly written feed item somewhere outside the feed,
+such as a webhook. Notifiers are built from config at startup by
+buildNotifiers and called by dispatchNotifications.
+*/
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, item RssItem, severity string) error
+}
+
+var (
+	notifiers           []Notifier
+	escalationNotifiers []Notifier
+)
+
+/*
+buildNotifiers returns a notifier for each destination configured in
+config. The result is empty when none are configured.
+*/
+func buildNotifiers(config *Config) []Notifier {
+	result := []Notifier{}
+
+	if config.NotifyWebhookURL != "" {
+		result = append(result, &WebhookNotifier{URL: config.NotifyWebhookURL})
+	}
+
+	return result
+}
+
+/*
+buildEscalationNotifiers returns the notifiers that receive escalations
+for outages lasting Config.EscalationAfter.
+*/
+func buildEscalationNotifiers(config *Config) []Notifier {
+	result := []Notifier{}
+
+	if config.EscalationWebhookURL != "" {
+		result = append(result, &WebhookNotifier{URL: config.EscalationWebhookURL})
+	}
+
+	return result
+}
+
+/*
+dispatchNotifications sends item to every configured notifier. A failing
+notifier is logged and does not stop the others.
+*/
+func dispatchNotifications(item RssItem) {
+	var (
+		err error
+	)
+
+	for _, notifier := range notifiers {
+		ctx, cancel := getContext()
+
+		if err = notifier.Notify(ctx, item, item.Severity); err != nil {
+			slog.Error("error sending notification", "notifier", notifier.Name(), "error", err)
+		}
+
+		cancel()
+	}
+}
+
+type NotificationResult struct {
+	Notifier string `json:"notifier"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
+
+/*
+adminTestNotifyHandler sends a sample item through every configured
+notifier so integrations can be checked without waiting for an outage.
+It reports whether each notifier succeeded.
+*/
+func adminTestNotifyHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err error
+		)
+
+		item := generateTestNotificationItem()
+		result := []NotificationResult{}
+
+		for _, notifier := range notifiers {
+			ctx, cancel := getContext()
+			err = notifier.Notify(ctx, item, item.Severity)
+			cancel()
+
+			entry := NotificationResult{Notifier: notifier.Name(), Success: err == nil}
+
+			if err != nil {
+				slog.Error("error sending test notification", "notifier", notifier.Name(), "error", err)
+				entry.Error = err.Error()
+			}
+
+			result = append(result, entry)
+		}
+
+		writeJsonOK(w, r, result)
+	}
+}
+
+func generateTestNotificationItem() RssItem {
+	return RssItem{
+		Title:       "Test notification",
+		Link:        config.StatusPageURL,
+		Description: `<p>This is a test notification sent from the admin endpoint. No services have changed.</p>`,
+		PubDate:     time.Now().UTC(),
+		Severity:    SeverityInfo,
+	}
+}
+
+/*
+WebhookNotifier posts each feed item as JSON to URL.
+*/
+type WebhookNotifier struct {
+	URL string
+}
+
+type WebhookPayload struct {
+	Title       string    `json:"title"`
+	Link        string    `json:"link"`
+	Description string    `json:"description"`
+	PubDate     time.Time `json:"pubDate"`
+	Severity    string    `json:"severity"`
+}
+
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, item RssItem, severity string) error {
+	var (
+		err      error
+		b        []byte
+		request  *http.Request
+		response *http.Response
+	)
+
+	payload := WebhookPayload{
+		Title:       item.Title,
+		Link:        item.Link,
+		Description: item.Description,
+		PubDate:     item.PubDate,
+		Severity:    severity,
+	}
+
+	if b, err = json.Marshal(payload); err != nil {
+		return fmt.Errorf("error marshalling webhook payload: %w", err)
+	}
+
+	if request, err = http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(b)); err != nil {
+		return fmt.Errorf("error creating webhook request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	if response, err = http.DefaultClient.Do(request); err != nil {
+		return fmt.Errorf("error posting webhook: %w", err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("webhook returned status code %d: %s", response.StatusCode, string(message))
+	}
+
+	return nil
+}
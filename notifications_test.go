@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+webhookRecorder is a webhook endpoint that keeps every payload posted to
+it.
+*/
+type webhookRecorder struct {
+	sync.Mutex
+	payloads []WebhookPayload
+}
+
+func serveWebhook(t *testing.T, status int) (*httptest.Server, *webhookRecorder) {
+	t.Helper()
+
+	recorder := &webhookRecorder{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			payload WebhookPayload
+		)
+
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected a JSON POST, got %s %q", r.Method, r.Header.Get("Content-Type"))
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("error decoding webhook payload: %v", err)
+		}
+
+		recorder.Lock()
+		recorder.payloads = append(recorder.payloads, payload)
+		recorder.Unlock()
+
+		w.WriteHeader(status)
+		w.Write([]byte("webhook says no"))
+	}))
+
+	t.Cleanup(server.Close)
+	return server, recorder
+}
+
+func (r *webhookRecorder) Payloads() []WebhookPayload {
+	r.Lock()
+	defer r.Unlock()
+
+	return append([]WebhookPayload{}, r.payloads...)
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	server, recorder := serveWebhook(t, http.StatusAccepted)
+
+	item := RssItem{
+		Title:       "1 services reporting potential issues",
+		Link:        "https://www.shopifystatus.com",
+		Description: "<p>Storefront - Outage</p>",
+		PubDate:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	notifier := &WebhookNotifier{URL: server.URL}
+
+	if err := notifier.Notify(context.Background(), item, SeverityMajor); err != nil {
+		t.Fatalf("error notifying: %v", err)
+	}
+
+	payloads := recorder.Payloads()
+
+	want := WebhookPayload{Title: item.Title, Link: item.Link, Description: item.Description, PubDate: item.PubDate, Severity: SeverityMajor}
+
+	if len(payloads) != 1 || payloads[0] != want {
+		t.Errorf("expected %+v, got %+v", want, payloads)
+	}
+}
+
+func TestWebhookNotifierReportsFailure(t *testing.T) {
+	server, _ := serveWebhook(t, http.StatusInternalServerError)
+
+	err := (&WebhookNotifier{URL: server.URL}).Notify(context.Background(), RssItem{}, SeverityInfo)
+
+	if err == nil || !strings.Contains(err.Error(), "500") || !strings.Contains(err.Error(), "webhook says no") {
+		t.Errorf("expected the status code and response in the error, got %v", err)
+	}
+}
+
+func TestNewFeedItemsAreSentToNotifiers(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	server, recorder := serveWebhook(t, http.StatusOK)
+	config.NotifyWebhookURL = server.URL
+	notifiers = buildNotifiers(config)
+
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, testPageOutage)
+	runTestCheck(t, testPageOutage)
+
+	payloads := recorder.Payloads()
+
+	if len(payloads) != 2 || payloads[1].Title != "1 services reporting potential issues" {
+		t.Errorf("expected one notification per feed item, got %+v", payloads)
+	}
+}
+
+func TestBuildNotifiers(t *testing.T) {
+	if got := buildNotifiers(&Config{}); len(got) != 0 {
+		t.Errorf("expected no notifiers by default, got %d", len(got))
+	}
+
+	if got := buildNotifiers(&Config{NotifyWebhookURL: "https://hooks.example.com/abc"}); len(got) != 1 || got[0].Name() != "webhook" {
+		t.Errorf("expected a webhook notifier, got %+v", got)
+	}
+}
+
+func TestAdminConfigHandlerMasksNotifyWebhookURL(t *testing.T) {
+	setTestConfig(t, &Config{NotifyWebhookURL: "https://hooks.example.com/notify-secret"})
+
+	w := httptest.NewRecorder()
+	adminConfigHandler()(w, httptest.NewRequest(http.MethodGet, "/admin/config", nil))
+
+	if strings.Contains(w.Body.String(), "notify-secret") {
+		t.Errorf("expected the webhook URL to be masked in %s", w.Body.String())
+	}
+}
+
+func TestAdminTestNotifyHandler(t *testing.T) {
+	var (
+		results []NotificationResult
+	)
+
+	setTestConfig(t, &Config{StatusPageURL: "https://www.shopifystatus.com"})
+	t.Cleanup(resetTestState)
+
+	working, recorder := serveWebhook(t, http.StatusOK)
+	failing, _ := serveWebhook(t, http.StatusBadGateway)
+
+	notifiers = []Notifier{&WebhookNotifier{URL: working.URL}, &WebhookNotifier{URL: failing.URL}}
+
+	w := httptest.NewRecorder()
+	adminTestNotifyHandler()(w, httptest.NewRequest(http.MethodPost, "/admin/notify/test", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("error decoding results: %v", err)
+	}
+
+	if len(results) != 2 || !results[0].Success || results[1].Success || !strings.Contains(results[1].Error, "502") {
+		t.Errorf("expected one success and one failure, got %+v", results)
+	}
+
+	if payloads := recorder.Payloads(); len(payloads) != 1 || payloads[0].Title != "Test notification" {
+		t.Errorf("expected the test item to be delivered, got %+v", payloads)
+	}
+}
+
+func TestAdminTestNotifyHandlerWithoutNotifiers(t *testing.T) {
+	setTestConfig(t, &Config{})
+
+	w := httptest.NewRecorder()
+	adminTestNotifyHandler()(w, httptest.NewRequest(http.MethodPost, "/admin/notify/test", nil))
+
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("expected an empty result list, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/*
+trackOutage records when the current outage started. An outage starts
+when a check first finds any service in error and ends when a check
+finds none, however the affected services change in between. Starting
+or ending an outage clears its follow-up and escalation flags, so each
+outage gets at most one of each. lastStatus is updated to match.
+*/
+func trackOutage(lastStatus *LastStatus, states ParsedStatusCollection) {
+	var (
+		err error
+	)
+
+	switch {
+	case states.HasErrors() && lastStatus.OutageStartedAt.IsZero():
+		lastStatus.OutageStartedAt = time.Now()
+
+	case !states.HasErrors() && !lastStatus.OutageStartedAt.IsZero():
+		lastStatus.OutageStartedAt = time.Time{}
+
+	default:
+		return
+	}
+
+	lastStatus.FollowUpSent = false
+	lastStatus.EscalationSent = false
+
+	if err = updateOutageStart(lastStatus.OutageStartedAt); err != nil {
+		slog.Error("error recording outage start", "error", err)
+	}
+}
+
+/*
+outageDuration returns how long the current outage has lasted. Records
+from before outages were tracked fall back to the last status change.
+*/
+func outageDuration(lastStatus *LastStatus) time.Duration {
+	startedAt := lastStatus.OutageStartedAt
+
+	if startedAt.IsZero() {
+		startedAt = lastStatus.ChangedAt
+	}
+
+	if startedAt.IsZero() {
+		startedAt = lastStatus.UpdatedAt
+	}
+
+	return time.Since(startedAt)
+}
+
+func updateOutageStart(startedAt time.Time) error {
+	return withWriteRetry("update outage start", func() error {
+		ctx, cancel := getContext()
+		defer cancel()
+
+		_, err := gorm.G[LastStatus](db).
+			Where("id=1").
+			Select("outage_started_at", "follow_up_sent", "escalation_sent").
+			Updates(ctx, LastStatus{OutageStartedAt: startedAt})
+
+		return err
+	})
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/PuerkitoBio/goquery"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownZoneAbbreviation = errors.New("time zone abbreviation does not belong to the configured time zone")
+
+	pageUpdatedLayouts = []string{
+		time.RFC3339,
+		time.RFC1123,
+		time.RFC1123Z,
+		"January 2, 2006 3:04 PM MST",
+		"January 2, 2006 15:04 MST",
+		"Jan 2, 2006 3:04 PM MST",
+		"Jan 2, 2006 15:04 MST",
+		"2006-01-02 15:04:05 MST",
+		"2006-01-02 15:04:05",
+	}
+)
+
+/*
+parsePageUpdatedAt reads the "last updated" time the status page reports
+about itself from the element matched by Config.PageUpdatedSelector. A
+datetime attribute, as on a <time> element, is preferred over the text.
+Text such as "Last updated: ..." has everything up to the colon removed
+before parsing.
+*/
+func parsePageUpdatedAt(doc *goquery.Document) (time.Time, bool) {
+	var (
+		err    error
+		result time.Time
+	)
+
+	selection := doc.Find(config.PageUpdatedSelector).First()
+
+	if selection.Length() == 0 {
+		slog.Debug("page updated selector matched nothing", "selector", config.PageUpdatedSelector)
+		return result, false
+	}
+
+	value, ok := selection.Attr("datetime")
+
+	if !ok {
+		value = selection.Text()
+
+		if label, after, found := strings.Cut(value, ":"); found && strings.Contains(strings.ToLower(label), "updated") {
+			value = after
+		}
+	}
+
+	value = strings.TrimSpace(value)
+	location := pageUpdatedLocation()
+
+	for _, layout := range pageUpdatedLayouts {
+		if result, err = parsePageTime(layout, value, location); err == nil {
+			return result.UTC(), true
+		}
+
+		if errors.Is(err, ErrUnknownZoneAbbreviation) {
+			slog.Warn("status page updated time uses a zone abbreviation outside PAGE_UPDATED_TIMEZONE. ignoring it", "value", value, "timezone", location.String())
+			return time.Time{}, false
+		}
+	}
+
+	slog.Warn("unable to parse the status page's last updated time", "value", value)
+	return result, false
+}
+
+/*
+pageUpdatedLocation returns the zone set by Config.PageUpdatedTimezone,
+falling back to UTC when it is empty or unknown.
+*/
+func pageUpdatedLocation() *time.Location {
+	var (
+		err      error
+		location *time.Location
+	)
+
+	if config.PageUpdatedTimezone == "" {
+		return time.UTC
+	}
+
+	if location, err = time.LoadLocation(config.PageUpdatedTimezone); err != nil {
+		slog.Error("unknown page updated time zone. using UTC", "timezone", config.PageUpdatedTimezone, "error", err)
+		return time.UTC
+	}
+
+	return location
+}
+
+/*
+parsePageTime parses value in location. time.Parse gives a zone
+abbreviation it does not recognize, such as PST while in UTC, a zero
+offset, silently shifting the time by hours. Such values return
+ErrUnknownZoneAbbreviation instead.
+*/
+func parsePageTime(layout, value string, location *time.Location) (time.Time, error) {
+	var (
+		err    error
+		result time.Time
+	)
+
+	if result, err = time.ParseInLocation(layout, value, location); err != nil {
+		return result, err
+	}
+
+	if !strings.Contains(layout, "MST") {
+		return result, nil
+	}
+
+	name, offset := result.Zone()
+
+	if offset != 0 || name == "UTC" || name == "GMT" {
+		return result, nil
+	}
+
+	if locationName, _ := result.In(location).Zone(); locationName == name {
+		return result, nil
+	}
+
+	return result, fmt.Errorf("%w: %s", ErrUnknownZoneAbbreviation, name)
+}
+
+/*
+recordPageUpdatedAt stores the status page's own "last updated" time, so
+/healthz and /current.json can tell a fresh check from fresh data.
+*/
+func recordPageUpdatedAt(doc *goquery.Document) {
+	var (
+		err error
+	)
+
+	if config.PageUpdatedSelector == "" || doc == nil {
+		return
+	}
+
+	pageUpdatedAt, ok := parsePageUpdatedAt(doc)
+
+	if !ok {
+		return
+	}
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if _, err = gorm.G[LastStatus](db).Where("id=1").Update(ctx, "page_updated_at", pageUpdatedAt); err != nil {
+		slog.Error("error recording status page updated time", "error", err)
+	}
+}
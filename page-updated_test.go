@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParsePageUpdatedAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		html     string
+		want     time.Time
+		wantOK   bool
+	}{
+		{name: "datetime attribute", html: `<time datetime="2026-01-15T10:00:00-05:00">yesterday</time>`, want: time.Date(2026, 1, 15, 15, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "labelled text", html: `<time>Last updated: Thu, 15 Jan 2026 10:00:00 +0100</time>`, want: time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "abbreviation in configured zone", timezone: "America/New_York", html: `<time>January 15, 2026 10:00 AM EST</time>`, want: time.Date(2026, 1, 15, 15, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "daylight abbreviation in configured zone", timezone: "America/New_York", html: `<time>Jul 15, 2026 10:00 EDT</time>`, want: time.Date(2026, 7, 15, 14, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "abbreviation outside configured zone", timezone: "UTC", html: `<time>January 15, 2026 10:00 AM PST</time>`, wantOK: false},
+		{name: "utc abbreviation", html: `<time>2026-01-15 10:00:00 UTC</time>`, want: time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "no zone uses configured zone", timezone: "America/New_York", html: `<time>2026-01-15 10:00:00</time>`, want: time.Date(2026, 1, 15, 15, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "unparseable", html: `<time>a while ago</time>`, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, &Config{PageUpdatedSelector: "time", PageUpdatedTimezone: tt.timezone})
+
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+
+			if err != nil {
+				t.Fatalf("error parsing document: %v", err)
+			}
+
+			got, ok := parsePageUpdatedAt(doc)
+
+			if ok != tt.wantOK {
+				t.Fatalf("expected ok %v, got %v (%s)", tt.wantOK, ok, got)
+			}
+
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func incompleteTestStates() ParsedStatusCollection {
+	return ParsedStatusCollection{
+		{Service: nil, Status: &Status{Status: "Outage", ClassName: "down", IsError: true}},
+		{Service: &Service{ServiceName: "Checkout"}, Status: nil},
+		{Service: &Service{ServiceName: "Storefront"}, Status: &Status{Status: "Outage", ClassName: "down", IsError: true}},
+	}
+}
+
+func TestParsedStatusCollectionValidate(t *testing.T) {
+	if err := incompleteTestStates().Validate(); !errors.Is(err, ErrIncompleteStatus) {
+		t.Errorf("expected ErrIncompleteStatus, got %v", err)
+	}
+
+	if err := incompleteTestStates()[2:].Validate(); err != nil {
+		t.Errorf("expected a complete collection to validate, got %v", err)
+	}
+}
+
+func TestParsedStatusCollectionSkipsIncompleteEntries(t *testing.T) {
+	setTestConfig(t, &Config{StatusPageURL: "https://status.example.com", MajorSeverityFraction: 0.5})
+
+	states := incompleteTestStates()
+
+	if !states.HasErrors() {
+		t.Errorf("expected the complete error entry to count")
+	}
+
+	if names := states.ErrorServiceNames(); len(names) != 1 || names[0] != "Storefront" {
+		t.Errorf("expected only Storefront in error, got %v", names)
+	}
+
+	if hash := generateStatusHash(states); hash != generateStatusHash(states[2:]) {
+		t.Errorf("expected incomplete entries to leave the hash unchanged")
+	}
+
+	item := generateErrorFeedItem(states)
+
+	if strings.Contains(item.Description, "Checkout") || !strings.Contains(item.Description, "Storefront - Outage") {
+		t.Errorf("expected only the complete entry in the item, got %s", item.Description)
+	}
+
+	operational := generateOperationalFeedItem(ParsedStatusCollection{{Service: &Service{ServiceName: "Checkout"}}})
+
+	if strings.Contains(operational.Description, "Checkout") {
+		t.Errorf("expected an entry without a status to be left out, got %s", operational.Description)
+	}
+
+	if snapshot := states.Snapshot(); !strings.Contains(snapshot, `"serviceName":"Checkout"`) {
+		t.Errorf("expected incomplete entries to still serialize, got %s", snapshot)
+	}
+}
+
+func TestInsertServiceStatusHistorySkipsIncompleteEntries(t *testing.T) {
+	setupTestDB(t)
+	services, statuses := seedTestStatuses(t)
+
+	states := ParsedStatusCollection{
+		{Service: services[0], Status: nil},
+		{Service: nil, Status: statuses[0]},
+		{Service: services[1], Status: &Status{Status: "Unknown", ClassName: "sparkly"}},
+		{Service: services[1], Status: statuses[1]},
+	}
+
+	if err := insertServiceStatusHistory(states); err != nil {
+		t.Fatalf("error inserting history: %v", err)
+	}
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	history, err := gorm.G[ServiceStatus](db).Find(ctx)
+
+	if err != nil {
+		t.Fatalf("error querying history: %v", err)
+	}
+
+	if len(history) != 1 || history[0].ServiceID != services[1].ID || history[0].StatusID != statuses[1].ID {
+		t.Errorf("expected only the complete, known entry recorded, got %+v", history)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+/*
+insertPerServiceFeedItems writes one feed item for every service whose
+state changed since the previous snapshot: services that started reporting
+an error, changed error status, or recovered. Error items link to the
+incident page when one was found. The ID of the first item
+written is returned so the transition can reference it.
+*/
+func insertPerServiceFeedItems(previous []ServiceState, states ParsedStatusCollection, incidentLink string, scrape ScrapeMetadata) uint {
+	var (
+		err     error
+		id      uint
+		firstID uint
+	)
+
+	for _, item := range generatePerServiceFeedItems(previous, states) {
+		if item.Severity != SeverityRecovery {
+			item.Link = cmp.Or(incidentLink, item.Link)
+		}
+
+		item.Metadata = scrape.Comment()
+
+		slog.Info("service status changed. writing to feed", "title", item.Title)
+
+		if id, err = insertRssItem(item); err != nil {
+			slog.Error("error inserting RSS item", "title", item.Title, "error", err)
+			continue
+		}
+
+		if firstID == 0 {
+			firstID = id
+		}
+	}
+
+	return firstID
+}
+
+func generatePerServiceFeedItems(previous []ServiceState, states ParsedStatusCollection) []RssItem {
+	result := []RssItem{}
+	severity := states.Severity()
+
+	for _, status := range states {
+		if !status.IsComplete() {
+			continue
+		}
+
+		before, found := findServiceState(previous, status.Service.ServiceName)
+		wasError := found && before.IsError
+
+		switch {
+		case status.IsError() && (!wasError || before.ClassName != status.Status.ClassName):
+			result = append(result, generateServiceFeedItem(
+				status,
+				fmt.Sprintf("%s reporting %s", status.Service.ServiceName, status.Status.Status),
+				fmt.Sprintf(`<p>The Shopify status page reports <strong>%s</strong> for %s.</p>`, status.Status.Status, status.Service.ServiceName),
+				severity,
+			))
+
+		case !status.IsError() && wasError:
+			result = append(result, generateServiceFeedItem(
+				status,
+				fmt.Sprintf("%s is %s again", status.Service.ServiceName, strings.ToLower(status.Status.Status)),
+				fmt.Sprintf(`<p>The Shopify status page shows %s as %s.</p>`, status.Service.ServiceName, status.Status.Status),
+				SeverityRecovery,
+			))
+		}
+	}
+
+	return result
+}
+
+func generateServiceFeedItem(status ParsedStatus, title, body, severity string) RssItem {
+	var (
+		description = strings.Builder{}
+	)
+
+	fmt.Fprintf(&description, `<h2>%s</h2>`, title)
+	fmt.Fprintf(&description, `%s`, body)
+	writeServiceList(&description, ParsedStatusCollection{status})
+
+	return RssItem{
+		Title:       title,
+		Link:        config.StatusPageURL,
+		Description: description.String(),
+		PubDate:     time.Now().UTC(),
+		Severity:    severity,
+		Snapshot:    ParsedStatusCollection{status}.Snapshot(),
+	}
+}
+
+func findServiceState(states []ServiceState, serviceName string) (ServiceState, bool) {
+	for _, state := range states {
+		if state.ServiceName == serviceName {
+			return state, true
+		}
+	}
+
+	return ServiceState{}, false
+}
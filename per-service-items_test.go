@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestGeneratePerServiceFeedItems(t *testing.T) {
+	setTestConfig(t, &Config{MajorSeverityFraction: 0.5})
+
+	operational := &Status{Status: "Operational", ClassName: "ok"}
+	outage := &Status{Status: "Outage", ClassName: "down", IsError: true}
+
+	admin := &Service{ServiceName: "Admin"}
+	checkout := &Service{ServiceName: "Checkout"}
+	storefront := &Service{ServiceName: "Storefront"}
+	support := &Service{ServiceName: "Support"}
+
+	previous := []ServiceState{
+		{ServiceName: "Admin", Status: "Operational", ClassName: "ok"},
+		{ServiceName: "Checkout", Status: "Degraded", ClassName: "slow", IsError: true},
+		{ServiceName: "Storefront", Status: "Outage", ClassName: "down", IsError: true},
+		{ServiceName: "Support", Status: "Outage", ClassName: "down", IsError: true},
+	}
+
+	states := ParsedStatusCollection{
+		{Service: admin, Status: outage},
+		{Service: checkout, Status: outage},
+		{Service: storefront, Status: operational},
+		{Service: support, Status: outage},
+		{Service: &Service{ServiceName: "Incomplete"}},
+	}
+
+	want := []struct {
+		title    string
+		severity string
+	}{
+		{title: "Admin reporting Outage", severity: SeverityMajor},
+		{title: "Checkout reporting Outage", severity: SeverityMajor},
+		{title: "Storefront is operational again", severity: SeverityRecovery},
+	}
+
+	got := generatePerServiceFeedItems(previous, states)
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d items, got %d: %+v", len(want), len(got), got)
+	}
+
+	for i, item := range got {
+		if item.Title != want[i].title || item.Severity != want[i].severity {
+			t.Errorf("item %d: expected %q (%s), got %q (%s)", i, want[i].title, want[i].severity, item.Title, item.Severity)
+		}
+
+		if len(parseSnapshot(item.Snapshot)) != 1 {
+			t.Errorf("item %d: expected a snapshot of the one service", i)
+		}
+	}
+}
+
+func TestCronJobPerServiceItems(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.ItemGranularity = ItemGranularityPerService
+
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, testServicePage([2]string{"Checkout", "down"}, [2]string{"Storefront", "down"}))
+
+	feed := queryTestFeed(t)
+
+	if len(feed) != 3 {
+		t.Fatalf("expected the first item and one per affected service, got %d", len(feed))
+	}
+
+	transitions, err := queryStatusTransitions(1)
+
+	if err != nil || len(transitions) != 1 {
+		t.Fatalf("error querying transitions: %v", err)
+	}
+
+	if transitions[0].FeedID != feed[1].ID {
+		t.Errorf("expected the transition to reference the first per-service item %d, got %d", feed[1].ID, transitions[0].FeedID)
+	}
+}
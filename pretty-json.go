@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/adampresley/httphelpers/responses"
+)
+
+/*
+wantsPrettyJson reports whether the request asked for indented JSON
+with ?pretty=1 (or true).
+*/
+func wantsPrettyJson(r *http.Request) bool {
+	value := r.URL.Query().Get("pretty")
+	return value == "1" || value == "true"
+}
+
+/*
+writeJson writes value as JSON, indented when the request asks for it
+with ?pretty=1. Otherwise it is written compactly, as before.
+*/
+func writeJson(w http.ResponseWriter, r *http.Request, status int, value any) {
+	var (
+		err error
+		b   []byte
+	)
+
+	if !wantsPrettyJson(r) {
+		responses.Json(w, status, value)
+		return
+	}
+
+	if b, err = json.MarshalIndent(value, "", "  "); err != nil {
+		slog.Error("error marshalling indented JSON", "error", err)
+		responses.Json(w, status, value)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
+
+func writeJsonOK(w http.ResponseWriter, r *http.Request, value any) {
+	writeJson(w, r, http.StatusOK, value)
+}
+
+/*
+indentJsonBody re-indents an already rendered JSON document, such as a
+cached JSON Feed, when the request asks for it with ?pretty=1.
+*/
+func indentJsonBody(r *http.Request, b []byte) []byte {
+	var (
+		err      error
+		indented = bytes.Buffer{}
+	)
+
+	if !wantsPrettyJson(r) {
+		return b
+	}
+
+	if err = json.Indent(&indented, b, "", "  "); err != nil {
+		slog.Error("error indenting JSON", "error", err)
+		return b
+	}
+
+	return indented.Bytes()
+}
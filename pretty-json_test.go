@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteJsonOK(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "/current.json", want: `{"name":"Checkout"}`},
+		{target: "/current.json?pretty=0", want: `{"name":"Checkout"}`},
+		{target: "/current.json?pretty=1", want: "{\n  \"name\": \"Checkout\"\n}"},
+		{target: "/current.json?pretty=true", want: "{\n  \"name\": \"Checkout\"\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeJsonOK(w, httptest.NewRequest(http.MethodGet, tt.target, nil), map[string]string{"name": "Checkout"})
+
+			if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+				t.Errorf("expected a 200 JSON response, got %d %q", w.Code, w.Header().Get("Content-Type"))
+			}
+
+			if got := strings.TrimSpace(w.Body.String()); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIndentJsonBody(t *testing.T) {
+	body := []byte(`{"items":[1,2]}`)
+
+	if got := indentJsonBody(httptest.NewRequest(http.MethodGet, "/status.json", nil), body); string(got) != string(body) {
+		t.Errorf("expected the body unchanged, got %s", got)
+	}
+
+	if got := indentJsonBody(httptest.NewRequest(http.MethodGet, "/status.json?pretty=1", nil), body); string(got) != "{\n  \"items\": [\n    1,\n    2\n  ]\n}" {
+		t.Errorf("expected the body indented, got %s", got)
+	}
+
+	if got := indentJsonBody(httptest.NewRequest(http.MethodGet, "/status.json?pretty=1", nil), []byte("not json")); string(got) != "not json" {
+		t.Errorf("expected invalid JSON returned as is, got %s", got)
+	}
+}
+
+func TestStatusJsonHandlerPretty(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+	runTestCheck(t, testPageOperational)
+
+	w := httptest.NewRecorder()
+	statusJsonHandler()(w, httptest.NewRequest(http.MethodGet, "/status.json?pretty=1", nil))
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "\n  \"version\": ") {
+		t.Errorf("expected an indented JSON Feed, got %d: %s", w.Code, w.Body.String())
+	}
+}
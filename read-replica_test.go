@@ -0,0 +1,76 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestReadDSNServesFeedQueries(t *testing.T) {
+	setupTestDB(t)
+
+	/*
+	 * Reconnect with a read DSN, migrating the read database the same way
+	 * as the primary so each can be told apart by its rows.
+	 */
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+
+	config.ReadDSN = "file:" + filepath.Join(t.TempDir(), "read.db")
+
+	if err := connectDatabase(); err != nil {
+		t.Fatalf("error connecting with a read DSN: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := readDB.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	if readDB == db {
+		t.Fatalf("expected a separate read connection")
+	}
+
+	primary := db
+	db = readDB
+
+	if err := runMigrations(); err != nil {
+		t.Fatalf("error migrating read database: %v", err)
+	}
+
+	db = primary
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if err := gorm.G[Feed](readDB).Create(ctx, &Feed{Title: "Replicated item", PubDate: time.Now()}); err != nil {
+		t.Fatalf("error creating replicated item: %v", err)
+	}
+
+	seedTestStatuses(t)
+	runTestCheck(t, testPageOutage)
+
+	feed, err := queryFeed(10)
+
+	if err != nil {
+		t.Fatalf("error querying feed: %v", err)
+	}
+
+	if len(feed) != 1 || feed[0].Title != "Replicated item" {
+		t.Errorf("expected feed queries to read the replica, got %+v", feed)
+	}
+
+	written, err := queryFeedFrom(db, 10)
+
+	if err != nil {
+		t.Fatalf("error querying primary: %v", err)
+	}
+
+	if len(written) != 1 || written[0].Title != "1 services reporting potential issues" {
+		t.Errorf("expected the check to write to the primary, got %+v", written)
+	}
+}
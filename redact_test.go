@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedactDSN(t *testing.T) {
+	tests := map[string]string{
+		"file:./shopify-status-rss.db":                      "file:./shopify-status-rss.db",
+		"postgres://rss:s3cret@db:5432/rss?sslmode=disable": "postgres://rss:xxxxx@db:5432/rss?sslmode=disable",
+		"postgresql://rss@db/rss":                           "postgresql://rss@db/rss",
+		"host=db user=rss password=s3cret dbname=rss":       "host=db user=rss password=xxxxx dbname=rss",
+		"host=db password = 'with spaces' dbname=rss":       "host=db password = xxxxx dbname=rss",
+		"host=db PASSWORD=s3cret":                           "host=db PASSWORD=xxxxx",
+		"":                                                  "",
+	}
+
+	for dsn, want := range tests {
+		if got := redactDSN(dsn); got != want {
+			t.Errorf("%q: expected %q, got %q", dsn, want, got)
+		}
+	}
+}
+
+func TestAdminConfigHandlerMasksSecrets(t *testing.T) {
+	setTestConfig(t, &Config{
+		DSN:           "postgres://rss:dbpass@db/rss",
+		ReadDSN:       "host=replica password=replicapass",
+		AdminToken:    "admintoken",
+		AleticsToken:  "aleticstoken",
+		S3SecretKey:   "s3secret",
+		StatusPageURL: "https://www.shopifystatus.com",
+	})
+
+	w := httptest.NewRecorder()
+	adminConfigHandler()(w, httptest.NewRequest(http.MethodGet, "/admin/config", nil))
+
+	body := w.Body.String()
+
+	for _, secret := range []string{"dbpass", "replicapass", "admintoken", "aleticstoken", "s3secret"} {
+		if strings.Contains(body, secret) {
+			t.Errorf("expected %s to be masked in %s", secret, body)
+		}
+	}
+
+	if !strings.Contains(body, "https://www.shopifystatus.com") {
+		t.Errorf("expected unmasked settings to be returned, got %s", body)
+	}
+
+	if config.AdminToken != "admintoken" {
+		t.Errorf("expected the running config to be left alone")
+	}
+}
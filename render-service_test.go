@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGrabStatusPageThroughRenderService(t *testing.T) {
+	var (
+		requested map[string]string
+	)
+
+	setTestConfig(t, &Config{StatusPageURL: "https://www.shopifystatus.com", RenderTimeout: time.Second})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&requested)
+		w.Write([]byte(testServicePage([2]string{"Checkout", "ok"})))
+	}))
+	defer server.Close()
+
+	config.RenderServiceURL = server.URL
+
+	doc, _, err := grabStatusPage(config.StatusPageURL, PageValidators{})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if requested["url"] != config.StatusPageURL {
+		t.Errorf("expected the render service to be asked for %s, got %v", config.StatusPageURL, requested)
+	}
+
+	if doc.Find(serviceNameSelector).Text() != "Checkout" {
+		t.Errorf("expected the rendered page to be parsed")
+	}
+}
+
+func TestGrabStatusPageRenderServiceTimeout(t *testing.T) {
+	setTestConfig(t, &Config{StatusPageURL: "https://www.shopifystatus.com", RenderTimeout: 50 * time.Millisecond})
+
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config.RenderServiceURL = server.URL
+
+	if _, _, err := grabStatusPage(config.StatusPageURL, PageValidators{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the render request to time out, got %v", err)
+	}
+}
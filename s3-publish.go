@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+/*
+publishFeedToS3 renders the RSS feed and uploads it to an S3-compatible
+bucket so it can be served from a CDN. It does nothing unless
+Config.S3Endpoint and Config.S3Bucket are set.
+*/
+func publishFeedToS3() error {
+	var (
+		err  error
+		b    []byte
+		feed []*Feed
+	)
+
+	if config.S3Endpoint == "" || config.S3Bucket == "" {
+		return nil
+	}
+
+	/*
+	 * The feed was just written, so read it back from the primary rather
+	 * than a possibly lagging read replica.
+	 */
+	if feed, err = queryFeedFrom(db, 10); err != nil {
+		return fmt.Errorf("error querying feed for S3: %w", err)
+	}
+
+	if b, err = renderRssFeed(feed); err != nil {
+		return fmt.Errorf("error rendering feed for S3: %w", err)
+	}
+
+	return putS3Object(config.S3Bucket, config.S3Key, "application/xml", b)
+}
+
+/*
+putS3Object uploads an object using a path-style URL and an AWS Signature
+Version 4 signed request, which S3 and compatible stores such as MinIO and
+R2 accept.
+*/
+func putS3Object(bucket, key, contentType string, body []byte) error {
+	var (
+		err      error
+		u        *url.URL
+		request  *http.Request
+		response *http.Response
+	)
+
+	if u, err = url.Parse(strings.TrimRight(config.S3Endpoint, "/") + "/" + bucket + "/" + strings.TrimLeft(key, "/")); err != nil {
+		return fmt.Errorf("error building S3 object URL: %w", err)
+	}
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if request, err = http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(body)); err != nil {
+		return fmt.Errorf("error creating S3 request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", contentType)
+	signS3Request(request, body, time.Now().UTC())
+
+	if response, err = http.DefaultClient.Do(request); err != nil {
+		return fmt.Errorf("error uploading feed to S3: %w", err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("S3 upload returned status code %d: %s", response.StatusCode, string(message))
+	}
+
+	return nil
+}
+
+func signS3Request(request *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "content-type:" + request.Header.Get("Content-Type") + "\n" +
+		"host:" + request.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := dateStamp + "/" + config.S3Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signingKey := hmacSHA256([]byte("AWS4"+config.S3SecretKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, config.S3Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		config.S3AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSHA256(key []byte, value string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(value))
+	return h.Sum(nil)
+}
+
+func sha256Hex(value []byte) string {
+	sum := sha256.Sum256(value)
+	return hex.EncodeToString(sum[:])
+}
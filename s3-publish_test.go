@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestPublishFeedToS3(t *testing.T) {
+	var (
+		method, path, authorization, body string
+	)
+
+	setupTestDB(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		method, path, authorization, body = r.Method, r.URL.Path, r.Header.Get("Authorization"), string(b)
+	}))
+	defer server.Close()
+
+	config.S3Endpoint = server.URL + "/"
+	config.S3Bucket = "feeds"
+	config.S3Key = "/status.rss"
+	config.S3AccessKey = "access"
+	config.S3SecretKey = "secret"
+	config.S3Region = "us-east-1"
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if err := gorm.G[Feed](db).Create(ctx, &Feed{Title: "Service issues", PubDate: time.Now()}); err != nil {
+		t.Fatalf("error creating feed item: %v", err)
+	}
+
+	if err := publishFeedToS3(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if method != http.MethodPut || path != "/feeds/status.rss" {
+		t.Errorf("expected PUT /feeds/status.rss, got %s %s", method, path)
+	}
+
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=access/") {
+		t.Errorf("expected a signed request, got authorization %q", authorization)
+	}
+
+	if !strings.Contains(body, "<title>Service issues</title>") {
+		t.Errorf("expected the rendered feed to be uploaded, got %q", body)
+	}
+}
+
+func TestPutS3ObjectReportsFailure(t *testing.T) {
+	setTestConfig(t, &Config{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	config.S3Endpoint = server.URL
+
+	if err := putS3Object("feeds", "status.rss", "application/xml", []byte("<rss/>")); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected a 403 error, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSafeCronJobRecoversFromPanic(t *testing.T) {
+	var (
+		logs bytes.Buffer
+	)
+
+	setupTestDB(t)
+	services, statuses := seedTestStatuses(t)
+	serveStatusPage(t, testPageOperational)
+
+	previousLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previousLogger) })
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	/*
+	 * A nil service is dereferenced while pairing names on the page.
+	 */
+	result := safeCronJob(append([]*Service{nil}, services...), statuses)
+
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "status check panicked") {
+		t.Fatalf("expected the panic to be returned as an error, got %+v", result)
+	}
+
+	if !strings.Contains(logs.String(), "status check panicked") || !strings.Contains(logs.String(), "stack=") {
+		t.Errorf("expected the panic to be logged with its stack, got %s", logs.String())
+	}
+
+	/*
+	 * The next check runs normally.
+	 */
+	if result = safeCronJob(services, statuses); result.Err != nil || !result.Changed {
+		t.Errorf("expected the next check to succeed, got %+v", result)
+	}
+}
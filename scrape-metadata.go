@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*
+ScrapeMetadata describes the fetch that produced a feed item, so readers
+can judge how fresh it is.
+*/
+type ScrapeMetadata struct {
+	ScrapedAt time.Time
+	Latency   time.Duration
+	Source    string
+}
+
+/*
+Comment returns the metadata as an HTML comment to append to a feed item
+description, or an empty string unless Config.IncludeScrapeMetadata is
+set.
+*/
+func (m ScrapeMetadata) Comment() string {
+	if !config.IncludeScrapeMetadata || m.ScrapedAt.IsZero() {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"<!-- scrapedAt: %s, fetchLatency: %s, source: %s -->",
+		m.ScrapedAt.UTC().Format(time.RFC3339),
+		m.Latency.Round(time.Millisecond),
+		strings.ReplaceAll(m.Source, "--", "- -"),
+	)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScrapeMetadataComment(t *testing.T) {
+	scrapedAt := time.Date(2026, 3, 4, 5, 6, 7, 0, time.FixedZone("EST", -5*60*60))
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		metadata ScrapeMetadata
+		want     string
+	}{
+		{
+			name:     "disabled",
+			enabled:  false,
+			metadata: ScrapeMetadata{ScrapedAt: scrapedAt, Latency: time.Second, Source: "https://www.shopifystatus.com"},
+			want:     "",
+		},
+		{
+			name:     "no scrape",
+			enabled:  true,
+			metadata: ScrapeMetadata{},
+			want:     "",
+		},
+		{
+			name:     "enabled",
+			enabled:  true,
+			metadata: ScrapeMetadata{ScrapedAt: scrapedAt, Latency: 1234567 * time.Microsecond, Source: "https://www.shopifystatus.com"},
+			want:     "<!-- scrapedAt: 2026-03-04T10:06:07Z, fetchLatency: 1.235s, source: https://www.shopifystatus.com -->",
+		},
+		{
+			name:     "source cannot close the comment",
+			enabled:  true,
+			metadata: ScrapeMetadata{ScrapedAt: scrapedAt, Source: "https://example.com/--><script>"},
+			want:     "<!-- scrapedAt: 2026-03-04T10:06:07Z, fetchLatency: 0s, source: https://example.com/- -><script> -->",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, &Config{IncludeScrapeMetadata: tt.enabled})
+
+			if got := tt.metadata.Comment(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCronJobAppendsScrapeMetadata(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.IncludeScrapeMetadata = true
+	runTestCheck(t, testPageOutage)
+
+	feed := queryTestFeed(t)
+
+	if len(feed) != 1 || !strings.Contains(feed[0].Description, "<!-- scrapedAt: ") || !strings.Contains(feed[0].Description, "source: "+config.StatusPageURL+" -->") {
+		t.Errorf("expected the item to carry scrape metadata, got %+v", feed)
+	}
+}
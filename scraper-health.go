@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/*
+recordParseFailure counts a failed parse of the status page. Once more
+than Config.MismatchToleranceRuns failures occur in a row, a warning item
+is written so subscribers know the feed may not reflect Shopify's real
+status until parsing works again. Shorter glitches are only logged.
+*/
+func recordParseFailure(lastStatus *LastStatus, parseErr error) {
+	var (
+		err error
+	)
+
+	lastStatus.ParseFailures++
+
+	if err = updateParseFailures(lastStatus.ParseFailures); err != nil {
+		slog.Error("error recording parse failure", "error", err)
+		return
+	}
+
+	if lastStatus.BrokenNotified {
+		return
+	}
+
+	if lastStatus.ParseFailures <= config.MismatchToleranceRuns {
+		slog.Info("tolerating status page parse failure. last known state is still served", "failures", lastStatus.ParseFailures, "tolerance", config.MismatchToleranceRuns)
+		return
+	}
+
+	slog.Warn("status page can no longer be parsed. writing warning to feed", "failures", lastStatus.ParseFailures)
+
+	if _, err = insertRssItem(generateScraperBrokenFeedItem(parseErr)); err != nil {
+		slog.Error("error inserting scraper warning RSS item", "error", err)
+		return
+	}
+
+	if err = updateBrokenNotified(true); err != nil {
+		slog.Error("error recording scraper warning", "error", err)
+	}
+}
+
+/*
+recordParseRecovery resets the failure count after a successful parse,
+writing a "restored" item if subscribers were warned about the break.
+*/
+func recordParseRecovery(lastStatus *LastStatus) {
+	var (
+		err error
+	)
+
+	if lastStatus.BrokenNotified {
+		slog.Info("status page parsing restored. writing to feed", "failures", lastStatus.ParseFailures)
+
+		if _, err = insertRssItem(generateScraperRestoredFeedItem(lastStatus.ParseFailures)); err != nil {
+			slog.Error("error inserting scraper restored RSS item", "error", err)
+			return
+		}
+
+		if err = updateBrokenNotified(false); err != nil {
+			slog.Error("error clearing scraper warning", "error", err)
+		}
+	}
+
+	if err = updateParseFailures(0); err != nil {
+		slog.Error("error resetting parse failures", "error", err)
+	}
+}
+
+func generateScraperBrokenFeedItem(parseErr error) RssItem {
+	return RssItem{
+		Title: "Status monitoring may be broken",
+		Link:  config.StatusPageURL,
+		Description: fmt.Sprintf(`<h2>Status Monitoring May Be Broken</h2><p>The Shopify status page could not be read. Until
+			this is resolved, this feed may not reflect the current status of Shopify services.</p><p>%s</p>`, html.EscapeString(parseErr.Error())),
+		PubDate:  time.Now().UTC(),
+		Severity: SeverityInfo,
+	}
+}
+
+func generateScraperRestoredFeedItem(failures int) RssItem {
+	return RssItem{
+		Title: "Status monitoring restored",
+		Link:  config.StatusPageURL,
+		Description: fmt.Sprintf(`<h2>Status Monitoring Restored</h2><p>The Shopify status page can be read again after %d
+			failed checks. This feed once again reflects the current status of Shopify services.</p>`, failures),
+		PubDate:  time.Now().UTC(),
+		Severity: SeverityInfo,
+	}
+}
+
+func updateParseFailures(failures int) error {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	_, err := gorm.G[LastStatus](db).Where("id=1").Update(ctx, "parse_failures", failures)
+	return err
+}
+
+func updateBrokenNotified(notified bool) error {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	_, err := gorm.G[LastStatus](db).Where("id=1").Update(ctx, "broken_notified", notified)
+	return err
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseFailureWarnsOnceAndRecovers(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	brokenPage := testServicePage()
+
+	runTestCheck(t, testPageOperational)
+
+	if result := runTestCheck(t, brokenPage); result.Err == nil {
+		t.Fatalf("expected a page without services to fail parsing")
+	}
+
+	runTestCheck(t, brokenPage)
+
+	feed := queryTestFeed(t)
+
+	if len(feed) != 2 || feed[0].Title != "Status monitoring may be broken" {
+		t.Fatalf("expected one warning after repeated failures, got %d items", len(feed))
+	}
+
+	runTestCheck(t, testPageOperational)
+
+	feed = queryTestFeed(t)
+
+	if len(feed) != 3 || feed[0].Title != "Status monitoring restored" {
+		t.Fatalf("expected a restored item after recovery, got %d items", len(feed))
+	}
+
+	lastStatus, err := queryLastStatus()
+
+	if err != nil {
+		t.Fatalf("error querying last status: %v", err)
+	}
+
+	if lastStatus.ParseFailures != 0 || lastStatus.BrokenNotified {
+		t.Errorf("expected failures to be reset, got %d failures, notified %v", lastStatus.ParseFailures, lastStatus.BrokenNotified)
+	}
+}
+
+func TestParseFailureTolerance(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.MismatchToleranceRuns = 2
+	brokenPage := testServicePage()
+
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, brokenPage)
+	runTestCheck(t, brokenPage)
+
+	if feed := queryTestFeed(t); len(feed) != 1 {
+		t.Fatalf("expected failures within the tolerance to write nothing, got %d items", len(feed))
+	}
+
+	runTestCheck(t, brokenPage)
+
+	feed := queryTestFeed(t)
+
+	if len(feed) != 2 || feed[0].Title != "Status monitoring may be broken" {
+		t.Fatalf("expected a warning once the tolerance is exceeded, got %d items", len(feed))
+	}
+}
+
+func TestParseFailureToleranceResetsOnSuccess(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.MismatchToleranceRuns = 1
+	brokenPage := testServicePage()
+
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, brokenPage)
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, brokenPage)
+
+	if feed := queryTestFeed(t); len(feed) != 1 {
+		t.Errorf("expected failures separated by a success not to add up, got %d items", len(feed))
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+/*
+runStartupSelectorCheck runs checkSelectorsOnStartup and logs a failure
+prominently. It returns false when the service should refuse to start,
+which is only when the check failed and Config.FailOnSelectorMismatch is
+set.
+*/
+func runStartupSelectorCheck(services []*Service, statuses []*Status) bool {
+	var (
+		err error
+	)
+
+	if err = checkSelectorsOnStartup(services, statuses); err != nil {
+		slog.Error("!!! STARTUP SELECTOR CHECK FAILED. the feed will not update until the selectors are fixed !!!", "error", err)
+		return !config.FailOnSelectorMismatch
+	}
+
+	return true
+}
+
+/*
+checkSelectorsOnStartup fetches the status page once and confirms the
+selectors still find every known service and status icon, so a broken
+deployment shows up in the startup logs rather than at the first
+scheduled check. A page that cannot be fetched is only logged. A page
+that no longer parses is an error, which main treats as fatal when
+Config.FailOnSelectorMismatch is set.
+*/
+func checkSelectorsOnStartup(services []*Service, statuses []*Status) error {
+	var (
+		err error
+		doc *goquery.Document
+	)
+
+	if config.UpstreamFeedURL != "" {
+		return nil
+	}
+
+	if doc, _, err = grabStatusPage(config.StatusPageURL, PageValidators{}); err != nil {
+		slog.Warn("startup selector check skipped. unable to fetch the status page", "error", err)
+		return nil
+	}
+
+	if _, err = parsePageStatuses(doc, services, statuses); err != nil {
+		return fmt.Errorf("selectors no longer match the status page: %w", err)
+	}
+
+	slog.Info("startup selector check passed", "services", len(services))
+	return nil
+}
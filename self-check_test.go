@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestRunStartupSelectorCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		page         string
+		failOnError  bool
+		wantStarting bool
+	}{
+		{name: "matching page", page: testServicePage([2]string{"Checkout", "ok"}, [2]string{"Storefront", "down"}), failOnError: true, wantStarting: true},
+		{name: "mismatch refuses to start", page: testServicePage([2]string{"Checkout", "ok"}), failOnError: true, wantStarting: false},
+		{name: "mismatch only warns", page: testServicePage([2]string{"Checkout", "ok"}), failOnError: false, wantStarting: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			services, statuses := seedTestStatuses(t)
+
+			config.FailOnSelectorMismatch = tt.failOnError
+			serveStatusPage(t, tt.page)
+
+			if got := runStartupSelectorCheck(services, statuses); got != tt.wantStarting {
+				t.Errorf("expected %v, got %v", tt.wantStarting, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/adampresley/mux"
+)
+
+func TestSetupRouterAppliesTimeouts(t *testing.T) {
+	c := &Config{
+		IdleTimeout:  3 * time.Minute,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 20 * time.Second,
+	}
+
+	c.Host = "localhost:0"
+	setTestConfig(t, c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	router := setupRouter([]mux.Route{}, ctx, cancel)
+
+	if router.Server.ReadTimeout != c.ReadTimeout {
+		t.Errorf("expected read timeout %s, got %s", c.ReadTimeout, router.Server.ReadTimeout)
+	}
+
+	if router.Server.WriteTimeout != c.WriteTimeout {
+		t.Errorf("expected write timeout %s, got %s", c.WriteTimeout, router.Server.WriteTimeout)
+	}
+
+	if router.Server.IdleTimeout != c.IdleTimeout {
+		t.Errorf("expected idle timeout %s, got %s", c.IdleTimeout, router.Server.IdleTimeout)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestWriteServiceListItemRawClassNames(t *testing.T) {
+	status := ParsedStatus{
+		Service: &Service{ServiceName: "Checkout"},
+		Status:  &Status{Status: "Outage", ClassName: "text--outage", IsError: true},
+	}
+
+	tests := []struct {
+		name    string
+		include bool
+		want    string
+	}{
+		{name: "off", include: false, want: `<li>Checkout - Outage</li>`},
+		{name: "on", include: true, want: `<li>Checkout - Outage<!-- className: text- -outage --></li>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, &Config{IncludeRawClassNames: tt.include})
+
+			list := strings.Builder{}
+			writeServiceListItem(&list, status)
+
+			if list.String() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, list.String())
+			}
+		})
+	}
+}
+
+func TestServiceGroups(t *testing.T) {
+	setTestConfig(t, &Config{GroupSelector: "section.group"})
+
+	services := []*Service{{ServiceName: "Admin"}, {ServiceName: "Checkout"}, {ServiceName: "Storefront"}}
+	statuses := []*Status{{Status: "Operational", ClassName: "ok"}, {Status: "Outage", ClassName: "down", IsError: true}}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><body>
+		<section class="group"><h2>Sales &amp; &lt;Channels&gt;</h2>
+			<div class="flex-col"><p>Checkout</p><i class="down"></i></div>
+			<div class="flex-col"><p>Storefront</p><i class="ok"></i></div>
+		</section>
+		<div class="flex-col"><p>Admin</p><i class="ok"></i></div>
+	</body></html>`))
+
+	if err != nil {
+		t.Fatalf("error parsing document: %v", err)
+	}
+
+	states, err := parsePageStatuses(doc, services, statuses)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if groups := states.Groups(); len(groups) != 1 || groups[0] != "Sales & <Channels>" {
+		t.Fatalf("expected one group, got %q", groups)
+	}
+
+	got := renderServiceList(states, 0)
+	want := `<ul><li>Admin - Operational</li></ul>` +
+		`<h3>Sales &amp; &lt;Channels&gt;</h3><ul><li>Checkout - Outage</li><li>Storefront - Operational</li></ul>`
+
+	if got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestRenderServiceListWithoutGroups(t *testing.T) {
+	setTestConfig(t, &Config{})
+
+	states := ParsedStatusCollection{
+		{Service: &Service{ServiceName: "Admin"}, Status: &Status{Status: "Operational"}},
+		{Service: &Service{ServiceName: "Checkout"}, Status: &Status{Status: "Outage", IsError: true}},
+	}
+
+	want := `<ul><li>Admin - Operational</li><li>Checkout - Outage</li></ul>`
+
+	if got := renderServiceList(states, 0); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestWriteServiceListTruncates(t *testing.T) {
+	states := ParsedStatusCollection{}
+
+	for _, name := range []string{"Admin", "Checkout", "Storefront", "Support"} {
+		states = append(states, ParsedStatus{Service: &Service{ServiceName: name}, Status: &Status{Status: "Outage", IsError: true}})
+	}
+
+	full := `<ul><li>Admin - Outage</li><li>Checkout - Outage</li><li>Storefront - Outage</li><li>Support - Outage</li></ul>`
+
+	tests := []struct {
+		name      string
+		maxLength int
+		prefix    string
+		want      string
+	}{
+		{name: "no limit", maxLength: 0, want: full},
+		{name: "fits", maxLength: len(full), want: full},
+		{name: "drops from the end", maxLength: 90, want: `<ul><li>Admin - Outage</li><li>Checkout - Outage</li></ul><p>...and 2 more</p>`},
+		{name: "counts existing text", maxLength: 90, prefix: "<h2>Service issues</h2>", want: `<h2>Service issues</h2><ul><li>Admin - Outage</li></ul><p>...and 3 more</p>`},
+		{name: "too small for any", maxLength: 10, want: `<ul></ul><p>...and 4 more</p>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, &Config{MaxDescriptionLength: tt.maxLength})
+
+			description := strings.Builder{}
+			description.WriteString(tt.prefix)
+			writeServiceList(&description, states)
+
+			if description.String() != tt.want {
+				t.Errorf("expected\n%s\ngot\n%s", tt.want, description.String())
+			}
+		})
+	}
+}
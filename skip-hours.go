@@ -0,0 +1,93 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+/*
+RssSkipHours and RssSkipDays tell aggregators when not to poll the feed.
+Hours are 0-23 in GMT.
+*/
+type RssSkipHours struct {
+	Hours []int `xml:"hour"`
+}
+
+type RssSkipDays struct {
+	Days []string `xml:"day"`
+}
+
+var rssDays = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+/*
+parseSkipHours reads Config.SkipHours, a comma-separated list of GMT
+hours. Invalid and duplicate entries are logged and skipped. It returns
+nil when no hours are configured so the element is omitted.
+*/
+func parseSkipHours(value string) *RssSkipHours {
+	var (
+		err  error
+		hour int
+	)
+
+	result := &RssSkipHours{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		if hour, err = strconv.Atoi(entry); err != nil || hour < 0 || hour > 23 {
+			slog.Error("ignoring invalid skip hour", "entry", entry)
+			continue
+		}
+
+		if !slices.Contains(result.Hours, hour) {
+			result.Hours = append(result.Hours, hour)
+		}
+	}
+
+	if len(result.Hours) == 0 {
+		return nil
+	}
+
+	return result
+}
+
+/*
+parseSkipDays reads Config.SkipDays, a comma-separated list of day names.
+Names are matched case-insensitively and written the way RSS expects,
+such as Saturday. It returns nil when no days are configured.
+*/
+func parseSkipDays(value string) *RssSkipDays {
+	result := &RssSkipDays{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		index := slices.IndexFunc(rssDays, func(day string) bool { return strings.EqualFold(day, entry) })
+
+		if index < 0 {
+			slog.Error("ignoring invalid skip day", "entry", entry)
+			continue
+		}
+
+		if !slices.Contains(result.Days, rssDays[index]) {
+			result.Days = append(result.Days, rssDays[index])
+		}
+	}
+
+	if len(result.Days) == 0 {
+		return nil
+	}
+
+	return result
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseSkipHours(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []int
+	}{
+		{value: "", want: nil},
+		{value: " , ", want: nil},
+		{value: "0, 1,23", want: []int{0, 1, 23}},
+		{value: "2,2,3", want: []int{2, 3}},
+		{value: "-1,24,noon,5", want: []int{5}},
+		{value: "24", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got := parseSkipHours(tt.value)
+
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("expected nil, got %+v", got)
+				}
+
+				return
+			}
+
+			if got == nil || !slices.Equal(got.Hours, tt.want) {
+				t.Errorf("expected %v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseSkipDays(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "", want: nil},
+		{value: "saturday, SUNDAY", want: []string{"Saturday", "Sunday"}},
+		{value: "Monday,monday", want: []string{"Monday"}},
+		{value: "Funday,Friday", want: []string{"Friday"}},
+		{value: "Sat", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got := parseSkipDays(tt.value)
+
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("expected nil, got %+v", got)
+				}
+
+				return
+			}
+
+			if got == nil || !slices.Equal(got.Days, tt.want) {
+				t.Errorf("expected %v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRssFeedSkipHoursAndDays(t *testing.T) {
+	setTestConfig(t, &Config{})
+
+	b, err := renderRssFeed(nil)
+
+	if err != nil {
+		t.Fatalf("error rendering feed: %v", err)
+	}
+
+	if strings.Contains(string(b), "skipHours") || strings.Contains(string(b), "skipDays") {
+		t.Errorf("expected no skip elements when unset, got %s", b)
+	}
+
+	config.SkipHours = "1,2"
+	config.SkipDays = "sunday"
+
+	if b, err = renderRssFeed(nil); err != nil {
+		t.Fatalf("error rendering feed: %v", err)
+	}
+
+	for _, want := range []string{"<skipHours><hour>1</hour><hour>2</hour></skipHours>", "<skipDays><day>Sunday</day></skipDays>"} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("expected %s in %s", want, b)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"cmp"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/adampresley/httphelpers/responses"
+	"gorm.io/gorm"
+)
+
+/*
+SloReport compares availability over the last Days against
+Config.SLOTarget. Availability is the fraction of recorded service checks
+that were not in error. Checks that found a status with no Status row,
+such as the Unknown fallback, are not recorded, so they don't reduce
+availability even with Config.UnknownStatusIsError. ErrorBudgetRemaining is the share of the allowed
+unavailability (1 - target) not yet used, and goes negative once the
+budget is overspent.
+*/
+type SloReport struct {
+	Days                 int                `json:"days"`
+	Target               float64            `json:"target"`
+	Samples              int                `json:"samples"`
+	Availability         float64            `json:"availability"`
+	ErrorBudgetRemaining float64            `json:"errorBudgetRemaining"`
+	Services             []ServiceSloReport `json:"services"`
+}
+
+/*
+ServiceStatusCount is the number of recorded checks in which a service
+had a status, aggregated in the database so /slo.json doesn't load every
+history row.
+*/
+type ServiceStatusCount struct {
+	ServiceID uint
+	StatusID  uint
+	Samples   int
+}
+
+type ServiceSloReport struct {
+	ServiceName          string  `json:"serviceName"`
+	Samples              int     `json:"samples"`
+	Availability         float64 `json:"availability"`
+	ErrorBudgetRemaining float64 `json:"errorBudgetRemaining"`
+}
+
+/*
+sloJsonHandler reports availability and error budgets computed from the
+ServiceStatus history. The window defaults to 30 days and is set with
+?days=, up to a year.
+*/
+func sloJsonHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err      error
+			counts   []ServiceStatusCount
+			services []*Service
+			statuses []*Status
+		)
+
+		days := parsePositiveIntParam(r, "days", 30, 365)
+
+		if counts, err = queryServiceStatusCounts(time.Now().AddDate(0, 0, -days)); err != nil {
+			slog.Error("error querying service status history", "error", err)
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, "An unexpected error occurred while querying service status history")
+			return
+		}
+
+		if services, err = queryReportedServices(); err != nil {
+			slog.Error("error querying services", "error", err)
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, "An unexpected error occurred while querying services")
+			return
+		}
+
+		if statuses, err = queryStatuses(); err != nil {
+			slog.Error("error querying statuses", "error", err)
+			responses.JsonErrorMessage(w, http.StatusInternalServerError, "An unexpected error occurred while querying statuses")
+			return
+		}
+
+		writeJsonOK(w, r, buildSloReport(days, config.SLOTarget, counts, services, statuses))
+	}
+}
+
+func buildSloReport(days int, target float64, counts []ServiceStatusCount, services []*Service, statuses []*Status) SloReport {
+	errorStatuses := map[uint]bool{}
+	serviceNames := map[uint]string{}
+	samples := map[uint]int{}
+	failures := map[uint]int{}
+	totalSamples := 0
+	totalFailures := 0
+
+	for _, status := range statuses {
+		errorStatuses[status.ID] = status.IsError
+	}
+
+	for _, service := range services {
+		serviceNames[service.ID] = service.ServiceName
+	}
+
+	for _, entry := range counts {
+		samples[entry.ServiceID] += entry.Samples
+		totalSamples += entry.Samples
+
+		if errorStatuses[entry.StatusID] {
+			failures[entry.ServiceID] += entry.Samples
+			totalFailures += entry.Samples
+		}
+	}
+
+	result := SloReport{
+		Days:     days,
+		Target:   target,
+		Samples:  totalSamples,
+		Services: []ServiceSloReport{},
+	}
+
+	result.Availability, result.ErrorBudgetRemaining = availability(totalSamples, totalFailures, target)
+
+	for serviceID, count := range samples {
+		/*
+		 * History of a service removed from the database still counts
+		 * toward the totals, but has no name to report it under.
+		 */
+		if _, ok := serviceNames[serviceID]; !ok {
+			continue
+		}
+
+		service := ServiceSloReport{
+			ServiceName: serviceNames[serviceID],
+			Samples:     count,
+		}
+
+		service.Availability, service.ErrorBudgetRemaining = availability(count, failures[serviceID], target)
+		result.Services = append(result.Services, service)
+	}
+
+	slices.SortFunc(result.Services, func(a, b ServiceSloReport) int {
+		return cmp.Compare(a.ServiceName, b.ServiceName)
+	})
+
+	return result
+}
+
+/*
+availability returns the fraction of samples that succeeded and the
+remaining error budget for target. With no samples, availability is
+reported as 1 and the budget as untouched.
+*/
+func availability(samples, failures int, target float64) (float64, float64) {
+	if samples == 0 {
+		return 1, 1
+	}
+
+	result := float64(samples-failures) / float64(samples)
+
+	if target >= 1 {
+		if failures > 0 {
+			return result, 0
+		}
+
+		return result, 1
+	}
+
+	return result, 1 - (1-result)/(1-target)
+}
+
+/*
+insertServiceStatusHistory records the status of every service found by
+a check. These rows are the source for /slo.json. Statuses without a
+Status row, such as the Unknown fallback, are left out.
+*/
+func insertServiceStatusHistory(states ParsedStatusCollection) error {
+	history := []ServiceStatus{}
+
+	for _, state := range states {
+		/*
+		 * Unknown statuses are not stored, so they have no ID to record.
+		 */
+		if !state.IsComplete() || state.Status.ID == 0 {
+			continue
+		}
+
+		history = append(history, ServiceStatus{
+			ServiceID: state.Service.ID,
+			StatusID:  state.Status.ID,
+		})
+	}
+
+	if len(history) == 0 {
+		return nil
+	}
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	return gorm.G[ServiceStatus](db).CreateInBatches(ctx, &history, 100)
+}
+
+/*
+queryReportedServices returns every service, including disabled and
+deleted ones, so history recorded before a service was disabled is still
+reported under its name.
+*/
+func queryReportedServices() ([]*Service, error) {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	return gorm.G[*Service](readDB).
+		Scopes(func(db *gorm.Statement) {
+			db.Unscoped = true
+		}).
+		Find(ctx)
+}
+
+func queryServiceStatusCounts(since time.Time) ([]ServiceStatusCount, error) {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	return gorm.G[ServiceStatusCount](readDB).
+		Table("service_statuses").
+		Select("service_id, status_id, COUNT(*) AS samples").
+		Where("created_at >= ? AND deleted_at IS NULL", since).
+		Group("service_id, status_id").
+		Find(ctx)
+}
+
+func pruneServiceStatusHistory(cutoff time.Time) (int, error) {
+	ctx, cancel := getContext()
+	defer cancel()
+
+	return gorm.G[ServiceStatus](db).
+		Scopes(func(db *gorm.Statement) {
+			db.Unscoped = true
+		}).
+		Where("created_at < ?", cutoff).
+		Delete(ctx)
+}
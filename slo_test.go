@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestBuildSloReport(t *testing.T) {
+	services := []*Service{{Model: gorm.Model{ID: 1}, ServiceName: "Storefront"}, {Model: gorm.Model{ID: 2}, ServiceName: "Checkout"}}
+	statuses := []*Status{{Model: gorm.Model{ID: 1}, ClassName: "ok"}, {Model: gorm.Model{ID: 2}, ClassName: "down", IsError: true}}
+
+	counts := []ServiceStatusCount{
+		{ServiceID: 1, StatusID: 1, Samples: 100},
+		{ServiceID: 2, StatusID: 1, Samples: 99},
+		{ServiceID: 2, StatusID: 2, Samples: 1},
+	}
+
+	report := buildSloReport(30, 0.99, counts, services, statuses)
+
+	if report.Days != 30 || report.Target != 0.99 || report.Samples != 200 {
+		t.Errorf("unexpected report header %+v", report)
+	}
+
+	assertFloat(t, "availability", report.Availability, 0.995)
+	assertFloat(t, "error budget", report.ErrorBudgetRemaining, 0.5)
+
+	if len(report.Services) != 2 || report.Services[0].ServiceName != "Checkout" {
+		t.Fatalf("expected services sorted by name, got %+v", report.Services)
+	}
+
+	assertFloat(t, "Checkout availability", report.Services[0].Availability, 0.99)
+	assertFloat(t, "Checkout error budget", report.Services[0].ErrorBudgetRemaining, 0)
+	assertFloat(t, "Storefront availability", report.Services[1].Availability, 1)
+	assertFloat(t, "Storefront error budget", report.Services[1].ErrorBudgetRemaining, 1)
+}
+
+func TestAvailability(t *testing.T) {
+	tests := []struct {
+		name             string
+		samples          int
+		failures         int
+		target           float64
+		wantAvailability float64
+		wantBudget       float64
+	}{
+		{name: "no samples", samples: 0, failures: 0, target: 0.999, wantAvailability: 1, wantBudget: 1},
+		{name: "overspent budget", samples: 100, failures: 2, target: 0.99, wantAvailability: 0.98, wantBudget: -1},
+		{name: "perfect target met", samples: 10, failures: 0, target: 1, wantAvailability: 1, wantBudget: 1},
+		{name: "perfect target missed", samples: 10, failures: 1, target: 1, wantAvailability: 0.9, wantBudget: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAvailability, gotBudget := availability(tt.samples, tt.failures, tt.target)
+
+			assertFloat(t, "availability", gotAvailability, tt.wantAvailability)
+			assertFloat(t, "error budget", gotBudget, tt.wantBudget)
+		})
+	}
+}
+
+func TestSloJsonHandler(t *testing.T) {
+	var (
+		report SloReport
+	)
+
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	runTestCheck(t, testPageOperational)
+	runTestCheck(t, testPageOutage)
+
+	counts, err := queryServiceStatusCounts(time.Now().Add(-time.Hour))
+
+	if err != nil {
+		t.Fatalf("error querying status counts: %v", err)
+	}
+
+	if len(counts) != 3 {
+		t.Errorf("expected history aggregated into 3 service and status pairs, got %+v", counts)
+	}
+
+	w := httptest.NewRecorder()
+	sloJsonHandler()(w, httptest.NewRequest(http.MethodGet, "/slo.json?days=7", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	if err = json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("error decoding SLO report: %v", err)
+	}
+
+	if report.Days != 7 || report.Samples != 4 {
+		t.Errorf("expected 4 samples over 7 days, got %+v", report)
+	}
+
+	assertFloat(t, "availability", report.Availability, 0.75)
+}
+
+func TestSloJsonHandlerNamesDisabledServices(t *testing.T) {
+	var (
+		report SloReport
+	)
+
+	setupTestDB(t)
+	services, _ := seedTestStatuses(t)
+
+	runTestCheck(t, testPageOutage)
+
+	if err := updateServiceActive(services[1].ID, false); err != nil {
+		t.Fatalf("error disabling service: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	sloJsonHandler()(w, httptest.NewRequest(http.MethodGet, "/slo.json", nil))
+
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("error decoding SLO report: %v", err)
+	}
+
+	if len(report.Services) != 2 || report.Services[0].ServiceName != "Checkout" || report.Services[1].ServiceName != "Storefront" {
+		t.Errorf("expected the disabled service to keep its name, got %+v", report.Services)
+	}
+}
+
+func TestBuildSloReportSkipsUnknownServices(t *testing.T) {
+	services := []*Service{{Model: gorm.Model{ID: 1}, ServiceName: "Storefront"}}
+	statuses := []*Status{{Model: gorm.Model{ID: 1}, ClassName: "ok"}, {Model: gorm.Model{ID: 2}, ClassName: "down", IsError: true}}
+
+	counts := []ServiceStatusCount{
+		{ServiceID: 1, StatusID: 1, Samples: 3},
+		{ServiceID: 9, StatusID: 2, Samples: 1},
+	}
+
+	report := buildSloReport(30, 0.99, counts, services, statuses)
+
+	if report.Samples != 4 || len(report.Services) != 1 || report.Services[0].ServiceName != "Storefront" {
+		t.Errorf("expected the removed service to count only toward the totals, got %+v", report)
+	}
+
+	assertFloat(t, "availability", report.Availability, 0.75)
+}
+
+func assertFloat(t *testing.T, name string, got, want float64) {
+	t.Helper()
+
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("expected %s %v, got %v", name, want, got)
+	}
+}
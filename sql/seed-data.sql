@@ -4,6 +4,7 @@ INSERT INTO statuses (status, class_name, created_at, updated_at, is_error) VALU
 ('Partial Outage', 'text-partial-outage', date('now'), date('now'), true),
 ('Major Outage', 'text-major-outage', date('now'), date('now'), true),
 ('Maintenance', 'text-under-maintenance', date('now'), date('now'), true)
+ON CONFLICT (class_name) DO NOTHING
 ;
 
 INSERT INTO services (service_name, created_at, updated_at) VALUES
@@ -16,5 +17,6 @@ INSERT INTO services (service_name, created_at, updated_at) VALUES
 ('Support', date('now'), date('now')),
 ('Point of Sale', date('now'), date('now')),
 ('Oxygen', date('now'), date('now'))
+ON CONFLICT (service_name) DO NOTHING
 ;
 
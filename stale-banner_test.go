@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestRenderFeedStaleBannerFollowsLastSuccessfulCheck(t *testing.T) {
+	tests := []struct {
+		name          string
+		lastSuccessAt time.Time
+		itemAge       time.Duration
+		wantBanner    bool
+	}{
+		{name: "quiet page with recent checks", lastSuccessAt: time.Now(), itemAge: 48 * time.Hour, wantBanner: false},
+		{name: "recent item but checks stopped", lastSuccessAt: time.Now().Add(-2 * time.Hour), itemAge: time.Minute, wantBanner: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			config.StaleBannerAge = time.Hour
+
+			ctx, cancel := getContext()
+			defer cancel()
+
+			if err := gorm.G[LastStatus](db).Create(ctx, &LastStatus{LastSuccessAt: tt.lastSuccessAt}); err != nil {
+				t.Fatalf("error creating last status: %v", err)
+			}
+
+			if err := gorm.G[Feed](db).Create(ctx, &Feed{Title: "item", PubDate: time.Now().Add(-tt.itemAge)}); err != nil {
+				t.Fatalf("error creating feed item: %v", err)
+			}
+
+			b, _, err := renderFeed(feedFormatRss, 10)
+
+			if err != nil {
+				t.Fatalf("error rendering feed: %v", err)
+			}
+
+			if got := strings.Contains(string(b), "may be outdated"); got != tt.wantBanner {
+				t.Errorf("expected banner %v, got %v", tt.wantBanner, got)
+			}
+		})
+	}
+}
+
+func TestIsFeedStaleBeforeFirstCheck(t *testing.T) {
+	setTestConfig(t, &Config{StaleBannerAge: time.Hour})
+
+	if isFeedStale(time.Time{}) {
+		t.Errorf("expected a feed with no successful check to not be stale")
+	}
+}
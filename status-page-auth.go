@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+/*
+applyStatusPageAuth adds the credentials in Config.StatusPageAuth to a
+status page request. The value is either basic:<user>:<password> or
+bearer:<token>. Nothing is added when it is empty.
+*/
+func applyStatusPageAuth(request *http.Request, auth string) error {
+	if auth == "" {
+		return nil
+	}
+
+	scheme, credentials, _ := strings.Cut(auth, ":")
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		username, password, ok := strings.Cut(credentials, ":")
+
+		if !ok {
+			return fmt.Errorf("status page auth must be in the form basic:<user>:<password>")
+		}
+
+		request.SetBasicAuth(username, password)
+
+	case "bearer":
+		if credentials == "" {
+			return fmt.Errorf("status page auth must be in the form bearer:<token>")
+		}
+
+		request.Header.Set("Authorization", "Bearer "+credentials)
+
+	default:
+		return fmt.Errorf("unsupported status page auth scheme '%s'. use basic or bearer", scheme)
+	}
+
+	return nil
+}
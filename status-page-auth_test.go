@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestApplyStatusPageAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    string
+		want    string
+		wantErr bool
+	}{
+		{name: "none", auth: "", want: ""},
+		{name: "basic", auth: "basic:monitor:p:ss", want: "Basic bW9uaXRvcjpwOnNz"},
+		{name: "bearer", auth: "Bearer:abc123", want: "Bearer abc123"},
+		{name: "basic without password", auth: "basic:monitor", wantErr: true},
+		{name: "empty bearer", auth: "bearer:", wantErr: true},
+		{name: "unknown scheme", auth: "digest:abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			err := applyStatusPageAuth(request, tt.auth)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if got := request.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("expected Authorization %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGrabStatusPageSendsStatusPageAuth(t *testing.T) {
+	setTestConfig(t, &Config{StatusPageAuth: "bearer:abc123"})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fmt.Fprint(w, testPageOperational)
+	}))
+	defer server.Close()
+
+	if _, _, err := grabStatusPage(server.URL, PageValidators{}); err != nil {
+		t.Errorf("expected the authenticated request to succeed, got %v", err)
+	}
+
+	config.StatusPageAuth = "digest:abc123"
+
+	if _, _, err := grabStatusPage(server.URL, PageValidators{}); err == nil {
+		t.Errorf("expected an invalid auth setting to fail the fetch")
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/*
+checkStillDown writes a single follow-up feed item once an outage has
+lasted Config.StillDownAfter. The outage is timed from its start, not the
+last change in which services are affected, and trackOutage resets the
+follow-up flag when an outage starts or ends, so each outage produces at
+most one follow-up.
+*/
+func checkStillDown(lastStatus *LastStatus, states ParsedStatusCollection, incidentLink string, scrape ScrapeMetadata) {
+	var (
+		err error
+	)
+
+	if config.StillDownAfter <= 0 || lastStatus.FollowUpSent {
+		return
+	}
+
+	if !meetsMinFeedSeverity(nil, states) {
+		return
+	}
+
+	downFor := outageDuration(lastStatus)
+
+	if downFor < config.StillDownAfter {
+		return
+	}
+
+	slog.Info("status page errors persist beyond threshold. writing follow-up to feed", "downFor", downFor, "threshold", config.StillDownAfter)
+
+	item := generateStillDownFeedItem(states, downFor)
+	item.Link = cmp.Or(incidentLink, item.Link)
+	item.Metadata = scrape.Comment()
+
+	if _, err = insertRssItem(item); err != nil {
+		slog.Error("error inserting follow-up RSS item", "error", err)
+		return
+	}
+
+	if err = markFollowUpSent(); err != nil {
+		slog.Error("error marking follow-up as sent", "error", err)
+	}
+}
+
+func generateStillDownFeedItem(states ParsedStatusCollection, downFor time.Duration) RssItem {
+	var (
+		description = strings.Builder{}
+	)
+
+	fmt.Fprintf(&description, `<h2>Shopify Issues Ongoing</h2>`)
+	fmt.Fprintf(&description, `<p>The Shopify status page has been reporting issues for %s. The
+		following services are still experiencing problems:</p>`, formatDownFor(downFor))
+	writeServiceList(&description, states.Errors())
+
+	return RssItem{
+		Title:       fmt.Sprintf("Still down after %s", formatDownFor(downFor)),
+		Link:        config.StatusPageURL,
+		Description: description.String(),
+		PubDate:     time.Now().UTC(),
+		Severity:    states.Severity(),
+		Snapshot:    states.Snapshot(),
+	}
+}
+
+/*
+formatDownFor renders a duration as whole hours and minutes, such as
+"2 hours" or "1 hour 15 minutes".
+*/
+func formatDownFor(d time.Duration) string {
+	var (
+		parts []string
+	)
+
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+
+	if hours == 1 {
+		parts = append(parts, "1 hour")
+	} else if hours > 1 {
+		parts = append(parts, fmt.Sprintf("%d hours", hours))
+	}
+
+	if minutes == 1 {
+		parts = append(parts, "1 minute")
+	} else if minutes > 1 || hours == 0 {
+		parts = append(parts, fmt.Sprintf("%d minutes", minutes))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func markFollowUpSent() error {
+	return withWriteRetry("mark follow-up sent", func() error {
+		ctx, cancel := getContext()
+		defer cancel()
+
+		_, err := gorm.G[LastStatus](db).Where("id=1").Update(ctx, "follow_up_sent", true)
+		return err
+	})
+}
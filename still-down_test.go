@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestStillDownFollowUpTimesTheWholeOutage(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.StillDownAfter = time.Hour
+	bothDown := testServicePage([2]string{"Checkout", "down"}, [2]string{"Storefront", "down"})
+
+	runTestCheck(t, testPageOutage)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if _, err := gorm.G[LastStatus](db).Where("id=1").Update(ctx, "outage_started_at", time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatalf("error backdating outage: %v", err)
+	}
+
+	/*
+	 * A change in which services are affected doesn't restart the outage.
+	 */
+	runTestCheck(t, bothDown)
+	runTestCheck(t, bothDown)
+	runTestCheck(t, bothDown)
+
+	feed := queryTestFeed(t)
+
+	if len(feed) != 3 {
+		t.Fatalf("expected two error items and one follow-up, got %d", len(feed))
+	}
+
+	if !strings.HasPrefix(feed[0].Title, "Still down after 2 hours") {
+		t.Errorf("expected a follow-up timed from the outage start, got %q", feed[0].Title)
+	}
+
+	/*
+	 * A recovery ends the outage, so the next one gets its own follow-up.
+	 */
+	runTestCheck(t, testPageOperational)
+
+	lastStatus, err := queryLastStatus()
+
+	if err != nil {
+		t.Fatalf("error querying last status: %v", err)
+	}
+
+	if !lastStatus.OutageStartedAt.IsZero() || lastStatus.FollowUpSent {
+		t.Errorf("expected the outage to be cleared, got started %s, follow-up sent %v", lastStatus.OutageStartedAt, lastStatus.FollowUpSent)
+	}
+}
+
+func TestFormatDownFor(t *testing.T) {
+	tests := map[time.Duration]string{
+		30 * time.Second:             "0 minutes",
+		time.Minute:                  "1 minute",
+		45 * time.Minute:             "45 minutes",
+		time.Hour:                    "1 hour",
+		time.Hour + time.Minute:      "1 hour 1 minute",
+		2*time.Hour + 15*time.Minute: "2 hours 15 minutes",
+		26 * time.Hour:               "26 hours",
+	}
+
+	for d, want := range tests {
+		if got := formatDownFor(d); got != want {
+			t.Errorf("%s: expected %q, got %q", d, want, got)
+		}
+	}
+}
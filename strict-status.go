@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+reportedStatusClasses remembers the unknown status classes already
+written to the feed by this process, so each new class produces one
+item rather than one per check.
+*/
+var reportedStatusClasses = struct {
+	sync.Mutex
+	classes []string
+}{}
+
+/*
+unknownStatusClasses returns the sorted, distinct class names of icons
+that matched no configured status.
+*/
+func unknownStatusClasses(states ParsedStatusCollection) []string {
+	result := []string{}
+
+	for _, state := range states {
+		if state.Status == nil || state.Status.ID != 0 || slices.Contains(result, state.Status.ClassName) {
+			continue
+		}
+
+		result = append(result, state.Status.ClassName)
+	}
+
+	slices.Sort(result)
+	return result
+}
+
+/*
+reportNewStatusClasses writes a feed item naming any unknown status
+classes not already reported, which usually means Shopify added a new
+status type that needs a Status row.
+*/
+func reportNewStatusClasses(classes []string) {
+	var (
+		err error
+	)
+
+	reportedStatusClasses.Lock()
+
+	newClasses := []string{}
+
+	for _, className := range classes {
+		if !slices.Contains(reportedStatusClasses.classes, className) {
+			newClasses = append(newClasses, className)
+		}
+	}
+
+	reportedStatusClasses.classes = append(reportedStatusClasses.classes, newClasses...)
+	reportedStatusClasses.Unlock()
+
+	if len(newClasses) == 0 {
+		return
+	}
+
+	slog.Warn("new status class detected on the status page", "classes", newClasses)
+
+	if _, err = insertRssItem(generateNewStatusClassFeedItem(newClasses)); err != nil {
+		slog.Error("error inserting new status class RSS item", "error", err)
+	}
+}
+
+func generateNewStatusClassFeedItem(classes []string) RssItem {
+	escaped := []string{}
+
+	for _, className := range classes {
+		escaped = append(escaped, "<li>"+html.EscapeString(className)+"</li>")
+	}
+
+	return RssItem{
+		Title: "New status class detected",
+		Link:  config.StatusPageURL,
+		Description: fmt.Sprintf(`<h2>New Status Class Detected</h2><p>The Shopify status page shows status icons that match
+			no known status. Checks fail until a status is added for them.</p><ul>%s</ul>`, strings.Join(escaped, "")),
+		PubDate:  time.Now().UTC(),
+		Severity: SeverityInfo,
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"gorm.io/gorm"
+)
+
+func TestUnknownStatusClasses(t *testing.T) {
+	states := ParsedStatusCollection{
+		{Status: &Status{Model: gorm.Model{ID: 1}, ClassName: "ok"}},
+		{Status: &Status{ClassName: "sparkly"}},
+		{Status: &Status{ClassName: "glowing"}},
+		{Status: &Status{ClassName: "sparkly"}},
+		{Status: nil},
+	}
+
+	if got := unknownStatusClasses(states); !slices.Equal(got, []string{"glowing", "sparkly"}) {
+		t.Errorf("expected sorted, distinct unknown classes, got %v", got)
+	}
+}
+
+func TestParsePageStatusesStrictStatusMatching(t *testing.T) {
+	setTestConfig(t, &Config{StrictStatusMatching: true})
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(testServicePage([2]string{"Checkout", "ok"}, [2]string{"Storefront", "sparkly"})))
+
+	if err != nil {
+		t.Fatalf("error parsing document: %v", err)
+	}
+
+	services := []*Service{{ServiceName: "Checkout"}, {ServiceName: "Storefront"}}
+	statuses := []*Status{{Model: gorm.Model{ID: 1}, Status: "Operational", ClassName: "ok"}}
+
+	_, err = parsePageStatuses(doc, services, statuses)
+
+	if !errors.Is(err, ErrUnknownStatusClass) || !strings.Contains(err.Error(), "sparkly") {
+		t.Errorf("expected an unknown status class error naming sparkly, got %v", err)
+	}
+}
+
+func TestCronJobReportsNewStatusClassOnce(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	config.StrictStatusMatching = true
+	page := testServicePage([2]string{"Checkout", "ok"}, [2]string{"Storefront", "sparkly"})
+
+	for range 2 {
+		if result := runTestCheck(t, page); !errors.Is(result.Err, ErrUnknownStatusClass) {
+			t.Fatalf("expected the check to fail on the unknown class, got %v", result.Err)
+		}
+	}
+
+	feed := queryTestFeed(t)
+
+	if len(feed) != 1 || feed[0].Title != "New status class detected" || !strings.Contains(feed[0].Description, "<li>sparkly</li>") {
+		t.Errorf("expected one new status class item naming sparkly, got %+v", feed)
+	}
+}
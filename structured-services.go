@@ -0,0 +1,34 @@
+package main
+
+/*
+ssrNamespace is the XML namespace of the ssr:service elements written to
+RSS items when Config.IncludeStructuredServices is on.
+*/
+const ssrNamespace = "https://github.com/adampresley/shopify-status-rss/ns/1.0"
+
+/*
+RssService is the state of one service when a feed item was written, for
+consumers that would rather not parse the HTML description.
+*/
+type RssService struct {
+	Name  string `xml:"name,attr"`
+	Error bool   `xml:"error,attr"`
+}
+
+/*
+structuredServices converts a feed item's stored snapshot into ssr:service
+elements. Items written before snapshots were stored, and items not about
+service states, have none.
+*/
+func structuredServices(snapshot string) []RssService {
+	result := []RssService{}
+
+	for _, state := range parseSnapshot(snapshot) {
+		result = append(result, RssService{
+			Name:  state.ServiceName,
+			Error: state.IsError,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStructuredServices(t *testing.T) {
+	got := structuredServices(`[{"serviceName":"Checkout","status":"Operational","className":"ok","isError":false},{"serviceName":"Storefront","status":"Outage","className":"down","isError":true}]`)
+	want := []RssService{{Name: "Checkout", Error: false}, {Name: "Storefront", Error: true}}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %+v, got %+v", want[i], got[i])
+		}
+	}
+
+	if got = structuredServices(""); len(got) != 0 {
+		t.Errorf("expected no services without a snapshot, got %+v", got)
+	}
+}
+
+func TestStatusRssIncludesStructuredServices(t *testing.T) {
+	setupTestDB(t)
+	seedTestStatuses(t)
+
+	runTestCheck(t, testPageOutage)
+
+	_, w := getTestRss(t, "/status.rss")
+
+	if strings.Contains(w.Body.String(), "ssr:service") {
+		t.Errorf("expected no structured services when disabled, got %s", w.Body.String())
+	}
+
+	config.IncludeStructuredServices = true
+	clearMemoryFeedCache()
+
+	if err := invalidateFeedCache(); err != nil {
+		t.Fatalf("error invalidating feed cache: %v", err)
+	}
+
+	_, w = getTestRss(t, "/status.rss")
+
+	for _, want := range []string{
+		`xmlns:ssr="` + ssrNamespace + `"`,
+		`<ssr:service name="Checkout" error="false"></ssr:service>`,
+		`<ssr:service name="Storefront" error="true"></ssr:service>`,
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("expected %s in %s", want, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummaryLine(t *testing.T) {
+	operational := &Status{Status: "Operational", ClassName: "ok"}
+	outage := &Status{Status: "Outage", ClassName: "down", IsError: true}
+
+	states := ParsedStatusCollection{
+		{Service: &Service{ServiceName: "Admin"}, Status: outage},
+		{Service: &Service{ServiceName: "Checkout"}, Status: operational},
+		{Service: &Service{ServiceName: "Storefront"}, Status: outage},
+	}
+
+	allOperational := ParsedStatusCollection{
+		{Service: &Service{ServiceName: "Checkout"}, Status: operational},
+		{Service: &Service{ServiceName: "Storefront"}, Status: operational},
+	}
+
+	tests := []struct {
+		name string
+		item func() RssItem
+		want string
+	}{
+		{name: "error item", item: func() RssItem { return generateErrorFeedItem(states) }, want: "<p><strong>2 of 3 services affected</strong></p>"},
+		{name: "operational item", item: func() RssItem { return generateOperationalFeedItem(allOperational) }, want: "<p><strong>0 of 2 services affected</strong></p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, &Config{MajorSeverityFraction: 0.5})
+
+			if description := tt.item().Description; strings.Contains(description, "services affected") {
+				t.Errorf("expected no summary line when disabled, got %s", description)
+			}
+
+			config.IncludeSummaryLine = true
+
+			if description := tt.item().Description; !strings.HasPrefix(description, tt.want) {
+				t.Errorf("expected the description to start with %s, got %s", tt.want, description)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+/*
+unsavedChanges holds what a check found while the database could not
+record it, when Config.BufferFailedWrites is on. lastStatus is the status
+as the monitor last saw it. statusPending is true when that status has
+not been written yet, and items are feed items whose insert failed. The
+next check that can reach the database writes them, so a transition
+during a brief outage isn't silently dropped.
+*/
+var unsaved = struct {
+	sync.Mutex
+	lastStatus    *LastStatus
+	statusPending bool
+	items         []RssItem
+}{}
+
+/*
+cachedLastStatus returns a copy of the last status held in memory, or nil
+when there is none or buffering is off.
+*/
+func cachedLastStatus() *LastStatus {
+	unsaved.Lock()
+	defer unsaved.Unlock()
+
+	if !config.BufferFailedWrites || unsaved.lastStatus == nil {
+		return nil
+	}
+
+	result := *unsaved.lastStatus
+	return &result
+}
+
+/*
+cacheLastStatus records the status a check ended with. pending marks it as
+not yet written to the database.
+*/
+func cacheLastStatus(lastStatus *LastStatus, pending bool) {
+	if !config.BufferFailedWrites {
+		return
+	}
+
+	unsaved.Lock()
+	defer unsaved.Unlock()
+
+	copied := *lastStatus
+	unsaved.lastStatus = &copied
+	unsaved.statusPending = pending
+}
+
+/*
+holdFeedItem keeps a feed item whose insert failed so the next check can
+write it.
+*/
+func holdFeedItem(item RssItem) {
+	if !config.BufferFailedWrites {
+		return
+	}
+
+	unsaved.Lock()
+	defer unsaved.Unlock()
+
+	unsaved.items = append(unsaved.items, item)
+	slog.Warn("holding feed item in memory until the database is reachable", "title", item.Title, "held", len(unsaved.items))
+}
+
+/*
+reconcileUnsavedChanges writes any status and feed items held in memory
+now that the database is reachable again. It returns the status the
+current check should compare against: the held status when there is one,
+otherwise lastStatus as read from the database.
+*/
+func reconcileUnsavedChanges(lastStatus *LastStatus) *LastStatus {
+	var (
+		err error
+	)
+
+	if !config.BufferFailedWrites {
+		return lastStatus
+	}
+
+	unsaved.Lock()
+	held := unsaved.lastStatus
+	pending := unsaved.statusPending
+	items := unsaved.items
+	unsaved.items = nil
+	unsaved.Unlock()
+
+	for _, item := range items {
+		if _, err = insertRssItem(item); err != nil {
+			slog.Error("error writing held feed item", "title", item.Title, "error", err)
+		}
+	}
+
+	if !pending || held == nil {
+		cacheLastStatus(lastStatus, false)
+		return lastStatus
+	}
+
+	if err = updateLastStatusSnapshot(held.LastStatusHash, held.Snapshot, PageValidators{ETag: held.ETag, LastModified: held.LastModified}); err != nil {
+		slog.Error("error writing held last status", "error", err)
+		return held
+	}
+
+	slog.Info("wrote status change held in memory during a database outage", "hash", held.LastStatusHash)
+
+	lastStatus.LastStatusHash = held.LastStatusHash
+	lastStatus.Snapshot = held.Snapshot
+	lastStatus.ChangedAt = time.Now()
+	lastStatus.ETag = held.ETag
+	lastStatus.LastModified = held.LastModified
+
+	cacheLastStatus(lastStatus, false)
+	return lastStatus
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+/*
+withUnreachableDB runs fn with db pointed at a closed connection, as if
+the database were down.
+*/
+func withUnreachableDB(t *testing.T, fn func()) {
+	t.Helper()
+
+	dialect, err := openDialector(config.DSN)
+
+	if err != nil {
+		t.Fatalf("error opening dialector: %v", err)
+	}
+
+	closed, err := gorm.Open(dialect, &gorm.Config{})
+
+	if err != nil {
+		t.Fatalf("error opening connection: %v", err)
+	}
+
+	sqlDB, err := closed.DB()
+
+	if err != nil {
+		t.Fatalf("error getting connection: %v", err)
+	}
+
+	_ = sqlDB.Close()
+
+	primary := db
+	db = closed
+	defer func() { db = primary }()
+
+	fn()
+}
+
+func TestBufferedWritesSurviveDatabaseOutage(t *testing.T) {
+	setupTestDB(t)
+	services, statuses := seedTestStatuses(t)
+
+	config.BufferFailedWrites = true
+	runTestCheck(t, testPageOperational)
+
+	/*
+	 * The status is held in memory once a check has read it back.
+	 */
+	runTestCheck(t, testPageOperational)
+	serveStatusPage(t, testPageOutage)
+
+	withUnreachableDB(t, func() {
+		if result := cronJob(services, statuses); !result.Changed {
+			t.Errorf("expected the outage to be detected against the status held in memory, got %+v", result)
+		}
+	})
+
+	if feed := queryTestFeed(t); len(feed) != 1 {
+		t.Fatalf("expected nothing written during the outage, got %d items", len(feed))
+	}
+
+	/*
+	 * The next check writes the held item and status, and doesn't report
+	 * the outage a second time.
+	 */
+	runTestCheck(t, testPageOutage)
+
+	feed := queryTestFeed(t)
+
+	if len(feed) != 2 || feed[0].Title != "1 services reporting potential issues" {
+		t.Errorf("expected the held outage item to be written, got %+v", feed)
+	}
+
+	lastStatus, err := queryLastStatus()
+
+	if err != nil {
+		t.Fatalf("error querying last status: %v", err)
+	}
+
+	if states := parseSnapshot(lastStatus.Snapshot); len(states) != 2 || !states[1].IsError {
+		t.Errorf("expected the held outage status to be written, got %+v", states)
+	}
+}
+
+func TestUnbufferedCheckFailsDuringDatabaseOutage(t *testing.T) {
+	setupTestDB(t)
+	services, statuses := seedTestStatuses(t)
+
+	runTestCheck(t, testPageOperational)
+	serveStatusPage(t, testPageOutage)
+
+	withUnreachableDB(t, func() {
+		if result := cronJob(services, statuses); result.Err == nil {
+			t.Errorf("expected the check to fail without buffering")
+		}
+	})
+
+	runTestCheck(t, testPageOutage)
+
+	if feed := queryTestFeed(t); len(feed) != 2 {
+		t.Errorf("expected the outage to be found by the next check, got %d items", len(feed))
+	}
+}
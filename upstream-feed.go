@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	ErrUpstreamFeedEmpty        = errors.New("upstream feed has no items")
+	ErrUpstreamFeedUnrecognized = errors.New("upstream feed has no item listing the state of known services")
+)
+
+/*
+upstreamFeed reads both RSS 2.0 and Atom documents. Only the fields needed
+to recover service statuses are kept.
+*/
+type upstreamFeed struct {
+	Items   []upstreamItem `xml:"channel>item"`
+	Entries []upstreamItem `xml:"entry"`
+}
+
+type upstreamItem struct {
+	Title       string            `xml:"title"`
+	Description string            `xml:"description"`
+	Content     string            `xml:"content"`
+	Services    []upstreamService `xml:"https://github.com/adampresley/shopify-status-rss/ns/1.0 service"`
+}
+
+/*
+upstreamService is an ssr:service element written by another instance of
+this server with Config.IncludeStructuredServices on.
+*/
+type upstreamService struct {
+	Name  string `xml:"name,attr"`
+	Error bool   `xml:"error,attr"`
+}
+
+/*
+fetchUpstreamStatuses reads the RSS or Atom feed of another monitor, such
+as another instance of this server, instead of scraping the status page.
+See parseUpstreamFeed for how the feed's items become service statuses.
+*/
+func fetchUpstreamStatuses(url string, services []*Service, statuses []*Status) (ParsedStatusCollection, error) {
+	var (
+		err      error
+		request  *http.Request
+		response *http.Response
+		body     io.Reader
+		b        []byte
+		feed     upstreamFeed
+	)
+
+	ctx, cancel := getContext()
+	defer cancel()
+
+	if request, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil); err != nil {
+		return nil, fmt.Errorf("error creating request for upstream feed '%s': %w", url, err)
+	}
+
+	if response, err = http.DefaultClient.Do(request); err != nil {
+		return nil, fmt.Errorf("error fetching upstream feed '%s': %w", url, err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("upstream feed '%s' returned status code %d", url, response.StatusCode)
+	}
+
+	if body, err = limitPageBody(response.Body, config.MaxPageBytes); err != nil {
+		return nil, fmt.Errorf("error reading upstream feed '%s': %w", url, err)
+	}
+
+	if b, err = io.ReadAll(body); err != nil {
+		return nil, fmt.Errorf("error reading upstream feed '%s': %w", url, err)
+	}
+
+	if err = xml.Unmarshal(b, &feed); err != nil {
+		return nil, fmt.Errorf("error parsing upstream feed '%s': %w", url, err)
+	}
+
+	return parseUpstreamFeed(feed, services, statuses)
+}
+
+/*
+parseUpstreamFeed recovers service statuses from the newest item that
+describes the state of the known services, skipping banners, warnings,
+and other items that don't. Feeds list their newest item first.
+
+An item's ssr:service elements are used when they name every known
+service. Items whose elements name only some, such as per-service items,
+are skipped. Items without them fall back to the service list in the
+description ("<li>Service - Status</li>"), which is only used when it
+lists every known service. Error, still-down, and per-service items list
+only some services, and reading the rest as operational would report a
+false recovery.
+*/
+func parseUpstreamFeed(feed upstreamFeed, services []*Service, statuses []*Status) (ParsedStatusCollection, error) {
+	var (
+		err    error
+		result ParsedStatusCollection
+		ok     bool
+	)
+
+	items := append(feed.Items, feed.Entries...)
+
+	if len(items) == 0 {
+		return ParsedStatusCollection{}, ErrUpstreamFeedEmpty
+	}
+
+	for _, item := range items {
+		if len(item.Services) > 0 {
+			if result, ok = parseUpstreamServices(item, services, statuses); ok {
+				return result, nil
+			}
+
+			continue
+		}
+
+		if result, ok, err = parseUpstreamServiceList(item, services, statuses); err != nil {
+			return result, err
+		}
+
+		if ok {
+			return result, nil
+		}
+	}
+
+	return ParsedStatusCollection{}, ErrUpstreamFeedUnrecognized
+}
+
+/*
+parseUpstreamServices maps an item's ssr:service elements onto the known
+services. The elements only say whether a service is in error, so the
+first error or non-error status stands in for the exact one. It reports
+false unless every known service is named.
+*/
+func parseUpstreamServices(item upstreamItem, services []*Service, statuses []*Status) (ParsedStatusCollection, bool) {
+	result := ParsedStatusCollection{}
+	errorStatus := firstErrorStatus(statuses)
+	operationalStatus := firstOperationalStatus(statuses)
+
+	for _, service := range services {
+		index := slices.IndexFunc(item.Services, func(s upstreamService) bool { return s.Name == service.ServiceName })
+
+		if index < 0 {
+			return result, false
+		}
+
+		status := operationalStatus
+
+		if item.Services[index].Error {
+			status = errorStatus
+		}
+
+		if status == nil {
+			continue
+		}
+
+		result = append(result, ParsedStatus{Service: service, Status: status})
+	}
+
+	return result, true
+}
+
+/*
+parseUpstreamServiceList maps the service list in an item's description
+onto the known services. It reports false unless the item lists every
+one of them.
+*/
+func parseUpstreamServiceList(item upstreamItem, services []*Service, statuses []*Status) (ParsedStatusCollection, bool, error) {
+	var (
+		err    error
+		doc    *goquery.Document
+		result = ParsedStatusCollection{}
+	)
+
+	if doc, err = goquery.NewDocumentFromReader(strings.NewReader(item.Description + item.Content)); err != nil {
+		return result, false, fmt.Errorf("error parsing upstream item '%s': %w", item.Title, err)
+	}
+
+	reported := map[string]*Status{}
+
+	doc.Find("li").Each(func(i int, s *goquery.Selection) {
+		text := strings.TrimSpace(s.Text())
+		separator := strings.LastIndex(text, " - ")
+
+		if separator < 0 {
+			return
+		}
+
+		serviceName := text[:separator]
+		statusName := strings.TrimSuffix(text[separator+3:], " (muted)")
+
+		for _, status := range statuses {
+			if strings.EqualFold(status.Status, statusName) {
+				reported[serviceName] = status
+				return
+			}
+		}
+
+		slog.Debug("upstream feed lists an unknown status", "service", serviceName, "status", statusName)
+	})
+
+	for _, service := range services {
+		status, ok := reported[service.ServiceName]
+
+		if !ok {
+			return result, false, nil
+		}
+
+		result = append(result, ParsedStatus{Service: service, Status: status})
+	}
+
+	return result, true, nil
+}
+
+func firstErrorStatus(statuses []*Status) *Status {
+	for _, status := range statuses {
+		if status.IsError {
+			return status
+		}
+	}
+
+	return nil
+}
+
+func firstOperationalStatus(statuses []*Status) *Status {
+	for _, status := range statuses {
+		if !status.IsError {
+			return status
+		}
+	}
+
+	return nil
+}
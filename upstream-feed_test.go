@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testUpstreamServices() ([]*Service, []*Status) {
+	statuses := []*Status{
+		{Status: "Operational", ClassName: "ok"},
+		{Status: "Outage", ClassName: "down", IsError: true},
+	}
+
+	services := []*Service{
+		{ServiceName: "Checkout", Active: true},
+		{ServiceName: "Storefront", Active: true},
+	}
+
+	return services, statuses
+}
+
+func statusByService(states ParsedStatusCollection) map[string]string {
+	result := map[string]string{}
+
+	for _, state := range states {
+		result[state.Service.ServiceName] = state.Status.Status
+	}
+
+	return result
+}
+
+func TestParseUpstreamFeedSkipsItemsWithoutServiceState(t *testing.T) {
+	setTestConfig(t, &Config{})
+	services, statuses := testUpstreamServices()
+
+	feed := upstreamFeed{
+		Items: []upstreamItem{
+			{Title: "Data as of yesterday — may be outdated", Description: "<p>Status checks may not be running.</p>"},
+			{Title: "Service issues", Description: "<ul><li>Checkout - Operational</li><li>Storefront - Outage (muted)</li></ul>"},
+			{Title: "All operational", Description: "<ul><li>Checkout - Operational</li><li>Storefront - Operational</li></ul>"},
+		},
+	}
+
+	got, err := parseUpstreamFeed(feed, services, statuses)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{"Checkout": "Operational", "Storefront": "Outage"}
+
+	if fmt.Sprint(statusByService(got)) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, statusByService(got))
+	}
+}
+
+func TestParseUpstreamFeedSkipsPartialServiceLists(t *testing.T) {
+	setTestConfig(t, &Config{})
+	services, statuses := testUpstreamServices()
+
+	/*
+	 * The still-down item only lists the service that is still in error,
+	 * so it must not be read as the other service having recovered.
+	 */
+	feed := upstreamFeed{
+		Items: []upstreamItem{
+			{Title: "Storefront is still down", Description: "<ul><li>Storefront - Outage</li></ul>"},
+			{Title: "Service issues", Description: "<ul><li>Checkout - Outage</li><li>Storefront - Outage</li></ul>"},
+		},
+	}
+
+	got, err := parseUpstreamFeed(feed, services, statuses)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{"Checkout": "Outage", "Storefront": "Outage"}
+
+	if fmt.Sprint(statusByService(got)) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, statusByService(got))
+	}
+
+	feed.Items = feed.Items[:1]
+
+	if _, err = parseUpstreamFeed(feed, services, statuses); !errors.Is(err, ErrUpstreamFeedUnrecognized) {
+		t.Errorf("expected ErrUpstreamFeedUnrecognized, got %v", err)
+	}
+}
+
+func TestParseUpstreamFeedErrors(t *testing.T) {
+	setTestConfig(t, &Config{})
+	services, statuses := testUpstreamServices()
+
+	if _, err := parseUpstreamFeed(upstreamFeed{}, services, statuses); !errors.Is(err, ErrUpstreamFeedEmpty) {
+		t.Errorf("expected ErrUpstreamFeedEmpty, got %v", err)
+	}
+
+	feed := upstreamFeed{Entries: []upstreamItem{{Title: "Warning", Content: "<ul><li>Payments - Outage</li></ul>"}}}
+
+	if _, err := parseUpstreamFeed(feed, services, statuses); !errors.Is(err, ErrUpstreamFeedUnrecognized) {
+		t.Errorf("expected ErrUpstreamFeedUnrecognized, got %v", err)
+	}
+}
+
+func TestParseUpstreamFeedReadsStructuredServices(t *testing.T) {
+	setTestConfig(t, &Config{IncludeStructuredServices: true})
+	services, statuses := testUpstreamServices()
+
+	checkout := ParsedStatus{Service: services[0], Status: statuses[0]}
+	storefront := ParsedStatus{Service: services[1], Status: statuses[1]}
+
+	/*
+	 * The per-service item names only one service and the full item's
+	 * description lists only the one in error, so only the ssr:service
+	 * elements of the full item give the whole state.
+	 */
+	b, err := renderRssFeed([]*Feed{
+		{ID: 3, Title: "Checkout is operational again", PubDate: time.Now(), Snapshot: ParsedStatusCollection{checkout}.Snapshot()},
+		{ID: 2, Title: "Service issues", PubDate: time.Now(), Description: "<ul><li>Storefront - Outage</li></ul>", Snapshot: ParsedStatusCollection{checkout, storefront}.Snapshot()},
+	})
+
+	if err != nil {
+		t.Fatalf("error rendering feed: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(b)
+	}))
+	defer server.Close()
+
+	got, err := fetchUpstreamStatuses(server.URL, services, statuses)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{"Checkout": "Operational", "Storefront": "Outage"}
+
+	if fmt.Sprint(statusByService(got)) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, statusByService(got))
+	}
+
+	var feed upstreamFeed
+
+	if err = xml.Unmarshal(b, &feed); err != nil {
+		t.Fatalf("error parsing rendered feed: %v", err)
+	}
+
+	if len(feed.Items) != 2 || len(feed.Items[1].Services) != 2 {
+		t.Errorf("expected ssr:service elements to be read, got %+v", feed.Items)
+	}
+}
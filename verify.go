@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+/*
+runVerify implements the "verify" subcommand. It compares the services
+and status classes in the last recorded snapshot with the active services
+and statuses in the database and reports any drift, such as after editing
+the service list before a deploy. It returns the process exit code: 1 on
+drift or error, otherwise 0.
+*/
+func runVerify() int {
+	var (
+		err        error
+		lastStatus *LastStatus
+		services   []*Service
+		statuses   []*Status
+	)
+
+	if lastStatus, err = queryLastStatus(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			fmt.Println("no snapshot has been recorded yet. nothing to verify")
+			return 0
+		}
+
+		slog.Error("error querying last status", "error", err)
+		return 1
+	}
+
+	if services, err = queryServices(); err != nil {
+		slog.Error("error querying services", "error", err)
+		return 1
+	}
+
+	if statuses, err = queryStatuses(); err != nil {
+		slog.Error("error querying statuses", "error", err)
+		return 1
+	}
+
+	snapshot := parseSnapshot(lastStatus.Snapshot)
+	snapshotOnly, databaseOnly := compareSnapshotServices(snapshot, services)
+	unknownClasses := unknownSnapshotClasses(snapshot, statuses)
+
+	for _, name := range snapshotOnly {
+		fmt.Printf("in snapshot but not an active service: %s\n", name)
+	}
+
+	for _, name := range databaseOnly {
+		fmt.Printf("active service missing from snapshot: %s\n", name)
+	}
+
+	for _, className := range unknownClasses {
+		fmt.Printf("status class in snapshot but not in the database: %s\n", className)
+	}
+
+	if len(snapshotOnly) > 0 || len(databaseOnly) > 0 || len(unknownClasses) > 0 {
+		fmt.Printf("drift detected: %d services only in the snapshot, %d only in the database, %d unknown status classes\n", len(snapshotOnly), len(databaseOnly), len(unknownClasses))
+		return 1
+	}
+
+	fmt.Printf("snapshot matches the %d active services\n", len(services))
+	return 0
+}
+
+/*
+compareSnapshotServices returns the sorted names of services found only
+in the snapshot and only in the database.
+*/
+func compareSnapshotServices(snapshot []ServiceState, services []*Service) ([]string, []string) {
+	snapshotOnly := []string{}
+	databaseOnly := []string{}
+
+	for _, state := range snapshot {
+		if !isKnownService(services, state.ServiceName) {
+			snapshotOnly = append(snapshotOnly, state.ServiceName)
+		}
+	}
+
+	for _, service := range services {
+		if _, found := findServiceState(snapshot, service.ServiceName); !found {
+			databaseOnly = append(databaseOnly, service.ServiceName)
+		}
+	}
+
+	slices.Sort(snapshotOnly)
+	slices.Sort(databaseOnly)
+	return snapshotOnly, databaseOnly
+}
+
+/*
+unknownSnapshotClasses returns the sorted, distinct status class names in
+the snapshot that match no status in the database. Muted services are
+ignored in snapshots written before muted services kept their real
+status.
+*/
+func unknownSnapshotClasses(snapshot []ServiceState, statuses []*Status) []string {
+	result := []string{}
+
+	for _, state := range snapshot {
+		if state.ClassName == "muted" || slices.Contains(result, state.ClassName) {
+			continue
+		}
+
+		if !slices.ContainsFunc(statuses, func(status *Status) bool { return status.ClassName == state.ClassName }) {
+			result = append(result, state.ClassName)
+		}
+	}
+
+	slices.Sort(result)
+	return result
+}
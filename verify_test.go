@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestRunVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		snapshot []ServiceState
+		wantCode int
+	}{
+		{
+			name: "matching snapshot",
+			snapshot: []ServiceState{
+				{ServiceName: "Checkout", Status: "Operational", ClassName: "ok"},
+				{ServiceName: "Storefront", Status: "Outage", ClassName: "down", IsError: true},
+			},
+			wantCode: 0,
+		},
+		{
+			name: "renamed service and unknown class",
+			snapshot: []ServiceState{
+				{ServiceName: "Checkout", Status: "Operational", ClassName: "ok"},
+				{ServiceName: "Online Store", Status: "Degraded", ClassName: "slow"},
+			},
+			wantCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			seedTestStatuses(t)
+
+			ctx, cancel := getContext()
+			defer cancel()
+
+			b, _ := json.Marshal(tt.snapshot)
+
+			if err := gorm.G[LastStatus](db).Create(ctx, &LastStatus{Snapshot: string(b)}); err != nil {
+				t.Fatalf("error creating last status: %v", err)
+			}
+
+			if code := runVerify(); code != tt.wantCode {
+				t.Errorf("expected exit code %d, got %d", tt.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestRunVerifyLeavesDatabaseUnmigrated(t *testing.T) {
+	connectTestDB(t)
+
+	if code := runVerify(); code != 1 {
+		t.Errorf("expected exit code 1 against an empty database, got %d", code)
+	}
+
+	if db.Migrator().HasTable(&LastStatus{}) {
+		t.Errorf("expected verify not to create tables")
+	}
+}